@@ -18,5 +18,486 @@
 
 package nanovgo
 
-// #include "nanovg/src/nanovg.c"
+/*
+#cgo linux LDFLAGS: -lm
+#include <stdint.h>
+#include "nanovg/src/nanovg.c"
+
+// A glyph of text drawn by a Go renderer with fonts, see textGlyph in
+// renderer.go.
+typedef struct nvgoGlyph {
+	int font;
+	int index;
+	unsigned int codepoint;
+	float x, y;
+	int advance;
+} nvgoGlyph;
+
+// The font data and metrics of a font, see textFont in renderer.go.
+typedef struct nvgoFontInfo {
+	const char* name;
+	const unsigned char* data;
+	int dataSize;
+	float unitsPerEm;
+	int ascent, descent;
+	int bounds[4];
+} nvgoFontInfo;
+
+// A path of the path cache, see FlatPath in flatten.go. Dashes of dashed
+// strokes have the index of their path as parent, paths have -1.
+typedef struct nvgoFlatPath {
+	float* points;
+	int npoints;
+	int closed, winding, convex;
+	NVGvertex* fill;
+	int nfill;
+	NVGvertex* fringe;
+	int nfringe;
+	NVGvertex* stroke;
+	int nstroke;
+	int parent;
+} nvgoFlatPath;
+
+// The render callbacks of Go renderers, exported from renderer.go. userPtr is
+// the ID of the renderer.
+extern int nvgoRenderCreate(uintptr_t uptr);
+extern int nvgoRenderCreateTexture(uintptr_t uptr, int type, int w, int h, int imageFlags, unsigned char* data);
+extern int nvgoRenderDeleteTexture(uintptr_t uptr, int image);
+extern int nvgoRenderUpdateTexture(uintptr_t uptr, int image, int x, int y, int w, int h, unsigned char* data);
+extern int nvgoRenderGetTextureSize(uintptr_t uptr, int image, int* w, int* h);
+extern void nvgoRenderViewport(uintptr_t uptr, float width, float height, float devicePixelRatio);
+extern void nvgoRenderCancel(uintptr_t uptr);
+extern void nvgoRenderFlush(uintptr_t uptr);
+extern void nvgoRenderFill(uintptr_t uptr, NVGpaint* paint, NVGcompositeOperationState compositeOperation, NVGscissor* scissor, float fringe, float* bounds, NVGpath* paths, int npaths);
+extern void nvgoRenderStroke(uintptr_t uptr, NVGpaint* paint, NVGcompositeOperationState compositeOperation, NVGscissor* scissor, float fringe, float strokeWidth, NVGpath* paths, int npaths);
+extern void nvgoRenderTriangles(uintptr_t uptr, NVGpaint* paint, NVGcompositeOperationState compositeOperation, NVGscissor* scissor, NVGvertex* verts, int nverts);
+extern void nvgoRenderDelete(uintptr_t uptr);
+extern int nvgoRenderDrawsText(uintptr_t uptr);
+extern void nvgoRenderText(uintptr_t uptr, NVGcontext* ctx, NVGpaint* paint, NVGcompositeOperationState compositeOperation, NVGscissor* scissor, float fontSize, float* xform, nvgoGlyph* glyphs, int nglyphs);
+
+static int nvgo__renderCreate(void* uptr)
+{
+	return nvgoRenderCreate((uintptr_t)uptr);
+}
+
+static int nvgo__renderCreateTexture(void* uptr, int type, int w, int h, int imageFlags, const unsigned char* data)
+{
+	return nvgoRenderCreateTexture((uintptr_t)uptr, type, w, h, imageFlags, (unsigned char*)data);
+}
+
+static int nvgo__renderDeleteTexture(void* uptr, int image)
+{
+	return nvgoRenderDeleteTexture((uintptr_t)uptr, image);
+}
+
+static int nvgo__renderUpdateTexture(void* uptr, int image, int x, int y, int w, int h, const unsigned char* data)
+{
+	return nvgoRenderUpdateTexture((uintptr_t)uptr, image, x, y, w, h, (unsigned char*)data);
+}
+
+static int nvgo__renderGetTextureSize(void* uptr, int image, int* w, int* h)
+{
+	return nvgoRenderGetTextureSize((uintptr_t)uptr, image, w, h);
+}
+
+static void nvgo__renderViewport(void* uptr, float width, float height, float devicePixelRatio)
+{
+	nvgoRenderViewport((uintptr_t)uptr, width, height, devicePixelRatio);
+}
+
+static void nvgo__renderCancel(void* uptr)
+{
+	nvgoRenderCancel((uintptr_t)uptr);
+}
+
+static void nvgo__renderFlush(void* uptr)
+{
+	nvgoRenderFlush((uintptr_t)uptr);
+}
+
+static void nvgo__renderFill(void* uptr, NVGpaint* paint, NVGcompositeOperationState compositeOperation, NVGscissor* scissor, float fringe,
+							 const float* bounds, const NVGpath* paths, int npaths)
+{
+	nvgoRenderFill((uintptr_t)uptr, paint, compositeOperation, scissor, fringe, (float*)bounds, (NVGpath*)paths, npaths);
+}
+
+static void nvgo__renderStroke(void* uptr, NVGpaint* paint, NVGcompositeOperationState compositeOperation, NVGscissor* scissor, float fringe,
+							   float strokeWidth, const NVGpath* paths, int npaths)
+{
+	nvgoRenderStroke((uintptr_t)uptr, paint, compositeOperation, scissor, fringe, strokeWidth, (NVGpath*)paths, npaths);
+}
+
+static void nvgo__renderTriangles(void* uptr, NVGpaint* paint, NVGcompositeOperationState compositeOperation, NVGscissor* scissor,
+								  const NVGvertex* verts, int nverts)
+{
+	nvgoRenderTriangles((uintptr_t)uptr, paint, compositeOperation, scissor, (NVGvertex*)verts, nverts);
+}
+
+static void nvgo__renderDelete(void* uptr)
+{
+	nvgoRenderDelete((uintptr_t)uptr);
+}
+
+NVGcontext* nvgoCreateInternal(uintptr_t id, int edgeAntiAlias)
+{
+	NVGparams params;
+	memset(&params, 0, sizeof(params));
+	params.renderCreate = nvgo__renderCreate;
+	params.renderCreateTexture = nvgo__renderCreateTexture;
+	params.renderDeleteTexture = nvgo__renderDeleteTexture;
+	params.renderUpdateTexture = nvgo__renderUpdateTexture;
+	params.renderGetTextureSize = nvgo__renderGetTextureSize;
+	params.renderViewport = nvgo__renderViewport;
+	params.renderCancel = nvgo__renderCancel;
+	params.renderFlush = nvgo__renderFlush;
+	params.renderFill = nvgo__renderFill;
+	params.renderStroke = nvgo__renderStroke;
+	params.renderTriangles = nvgo__renderTriangles;
+	params.renderDelete = nvgo__renderDelete;
+	params.userPtr = (void*)id;
+	params.edgeAntiAlias = edgeAntiAlias;
+	return nvgCreateInternal(&params);
+}
+
+int nvgoIsInternal(NVGcontext* ctx)
+{
+	return nvgInternalParams(ctx)->renderCreate == nvgo__renderCreate;
+}
+
+float nvgoGlobalAlpha(NVGcontext* ctx)
+{
+	return nvg__getState(ctx)->alpha;
+}
+
+// Returns the font a glyph was taken from, which is one of the fallback fonts
+// if the font has no glyph for the codepoint.
+static int nvgo__glyphFont(FONScontext* fs, int fontId, unsigned int codepoint, int index)
+{
+	FONSfont* font = fs->fonts[fontId];
+	int i;
+	if (fons__tt_getGlyphIndex(&font->font, codepoint) != 0)
+		return fontId;
+	for (i = 0; i < font->nfallbacks; i++) {
+		if (fons__tt_getGlyphIndex(&fs->fonts[font->fallbacks[i]]->font, codepoint) == index)
+			return font->fallbacks[i];
+	}
+	return fontId;
+}
+
+// nvgoText draws text like nvgText(), but hands the glyphs over to Go
+// renderers which draw text with fonts instead of the font atlas.
+float nvgoText(NVGcontext* ctx, float x, float y, const char* string, const char* end)
+{
+	NVGstate* state = nvg__getState(ctx);
+	FONStextIter iter;
+	FONSquad q;
+	NVGpaint paint;
+	nvgoGlyph* glyphs;
+	float scale, invscale;
+	int nglyphs = 0;
+
+	if (!nvgoIsInternal(ctx) || !nvgoRenderDrawsText((uintptr_t)ctx->params.userPtr))
+		return nvgText(ctx, x, y, string, end);
+
+	if (end == NULL)
+		end = string + strlen(string);
+
+	if (state->fontId == FONS_INVALID) return x;
+
+	scale = nvg__getFontScale(state) * ctx->devicePxRatio;
+	invscale = 1.0f / scale;
+	fonsSetSize(ctx->fs, state->fontSize*scale);
+	fonsSetSpacing(ctx->fs, state->letterSpacing*scale);
+	fonsSetBlur(ctx->fs, state->fontBlur*scale);
+	fonsSetAlign(ctx->fs, state->textAlign);
+	fonsSetFont(ctx->fs, state->fontId);
+
+	glyphs = (nvgoGlyph*)malloc(sizeof(nvgoGlyph) * nvg__maxi(1, (int)(end - string)));
+	if (glyphs == NULL) return x;
+
+	fonsTextIterInit(ctx->fs, &iter, x*scale, y*scale, string, end, FONS_GLYPH_BITMAP_OPTIONAL);
+	while (fonsTextIterNext(ctx->fs, &iter, &q)) {
+		nvgoGlyph* glyph = &glyphs[nglyphs];
+		FONSfont* font;
+		int advance, lsb;
+		short xadv;
+		if (iter.prevGlyphIndex == -1)
+			continue;
+		glyph->font = nvgo__glyphFont(ctx->fs, state->fontId, iter.codepoint, iter.prevGlyphIndex);
+		glyph->index = iter.prevGlyphIndex;
+		glyph->codepoint = iter.codepoint;
+		// Position the glyph at the pen position fontstash used for the quad,
+		// which is the rounded advance before the next pen position.
+		font = ctx->fs->fonts[glyph->font];
+		stbtt_GetGlyphHMetrics(&font->font.font, glyph->index, &advance, &lsb);
+		xadv = (short)(fons__tt_getPixelHeightScale(&font->font, (float)iter.isize/10.0f) * advance * 10.0f);
+		glyph->x = (iter.nextx - (int)(xadv / 10.0f + 0.5f)) * invscale;
+		glyph->y = iter.nexty * invscale;
+		glyph->advance = advance;
+		nglyphs++;
+	}
+
+	paint = state->fill;
+	paint.innerColor.a *= state->alpha;
+	paint.outerColor.a *= state->alpha;
+	nvgoRenderText((uintptr_t)ctx->params.userPtr, ctx, &paint, state->compositeOperation, &state->scissor,
+				   iter.isize / 10.0f * invscale, state->xform, glyphs, nglyphs);
+	free(glyphs);
+
+	return iter.nextx / scale;
+}
+
+// nvgoTextBox draws multi-line text like nvgTextBox(), with nvgoText().
+void nvgoTextBox(NVGcontext* ctx, float x, float y, float breakRowWidth, const char* string, const char* end)
+{
+	NVGstate* state = nvg__getState(ctx);
+	NVGtextRow rows[2];
+	int nrows = 0, i;
+	int oldAlign = state->textAlign;
+	int haling = state->textAlign & (NVG_ALIGN_LEFT | NVG_ALIGN_CENTER | NVG_ALIGN_RIGHT);
+	int valign = state->textAlign & (NVG_ALIGN_TOP | NVG_ALIGN_MIDDLE | NVG_ALIGN_BOTTOM | NVG_ALIGN_BASELINE);
+	float lineh = 0;
+
+	if (state->fontId == FONS_INVALID) return;
+
+	nvgTextMetrics(ctx, NULL, NULL, &lineh);
+
+	state->textAlign = NVG_ALIGN_LEFT | valign;
+
+	while ((nrows = nvgTextBreakLines(ctx, string, end, breakRowWidth, rows, 2))) {
+		for (i = 0; i < nrows; i++) {
+			NVGtextRow* row = &rows[i];
+			if (haling & NVG_ALIGN_LEFT)
+				nvgoText(ctx, x, y, row->start, row->end);
+			else if (haling & NVG_ALIGN_CENTER)
+				nvgoText(ctx, x + breakRowWidth*0.5f - row->width*0.5f, y, row->start, row->end);
+			else if (haling & NVG_ALIGN_RIGHT)
+				nvgoText(ctx, x + breakRowWidth - row->width, y, row->start, row->end);
+			y += lineh * state->lineHeight;
+		}
+		string = rows[nrows-1].next;
+	}
+
+	state->textAlign = oldAlign;
+}
+
+int nvgoGetFontInfo(NVGcontext* ctx, int fontId, nvgoFontInfo* info)
+{
+	FONSfont* font;
+	if (fontId < 0 || fontId >= ctx->fs->nfonts)
+		return 0;
+	font = ctx->fs->fonts[fontId];
+	info->name = font->name;
+	info->data = font->data;
+	info->dataSize = font->dataSize;
+	info->unitsPerEm = 1.0f / stbtt_ScaleForMappingEmToPixels(&font->font.font, 1.0f);
+	stbtt_GetFontVMetrics(&font->font.font, &info->ascent, &info->descent, NULL);
+	stbtt_GetFontBoundingBox(&font->font.font, &info->bounds[0], &info->bounds[1], &info->bounds[2], &info->bounds[3]);
+	return 1;
+}
+
+static NVGvertex* nvgo__copyVerts(const NVGvertex* verts, int nverts)
+{
+	NVGvertex* copy;
+	if (nverts <= 0)
+		return NULL;
+	copy = (NVGvertex*)malloc(sizeof(NVGvertex) * nverts);
+	if (copy != NULL)
+		memcpy(copy, verts, sizeof(NVGvertex) * nverts);
+	return copy;
+}
+
+// Appends a path of the path cache to paths, returning the added path.
+static nvgoFlatPath* nvgo__appendFlatPath(NVGcontext* ctx, const NVGpath* path, int parent, nvgoFlatPath** paths, int* npaths, int* cpaths)
+{
+	const NVGpoint* pts = &ctx->cache->points[path->first];
+	nvgoFlatPath* dst;
+	int i;
+
+	if (*npaths+1 > *cpaths) {
+		int c = *npaths+1 + *cpaths/2;
+		nvgoFlatPath* p = (nvgoFlatPath*)realloc(*paths, sizeof(nvgoFlatPath) * c);
+		if (p == NULL) return NULL;
+		*paths = p;
+		*cpaths = c;
+	}
+	dst = &(*paths)[(*npaths)++];
+	memset(dst, 0, sizeof(*dst));
+	dst->points = (float*)malloc(sizeof(float) * 2 * nvg__maxi(path->count, 1));
+	if (dst->points != NULL) {
+		for (i = 0; i < path->count; i++) {
+			dst->points[i*2] = pts[i].x;
+			dst->points[i*2+1] = pts[i].y;
+		}
+		dst->npoints = path->count;
+	}
+	dst->closed = path->closed;
+	dst->winding = path->winding;
+	dst->convex = path->convex;
+	dst->parent = parent;
+	return dst;
+}
+
+static void nvgo__expandStroke(NVGcontext* ctx, float strokeWidth)
+{
+	NVGstate* state = nvg__getState(ctx);
+	if (ctx->params.edgeAntiAlias && state->shapeAntiAlias)
+		nvg__expandStroke(ctx, strokeWidth*0.5f, ctx->fringeWidth, state->lineCap, state->lineJoin, state->miterLimit);
+	else
+		nvg__expandStroke(ctx, strokeWidth*0.5f, 0.0f, state->lineCap, state->lineJoin, state->miterLimit);
+}
+
+// nvgoFlattenPaths flattens the current path like nvgFill() and nvgStroke()
+// do, and returns copies of the paths of the path cache. If fill is set, the
+// fill and fringe vertices of the paths are expanded like nvgFill() does. If
+// stroke is set, the stroke vertices are expanded with the current stroke
+// style like nvgStroke() does, and the dashes of dashed strokes follow the
+// paths. The paths are freed with nvgoFreeFlatPaths().
+nvgoFlatPath* nvgoFlattenPaths(NVGcontext* ctx, int fill, int stroke, int* count)
+{
+	NVGstate* state = nvg__getState(ctx);
+	NVGpathCache* cache = ctx->cache;
+	nvgoFlatPath* paths = NULL;
+	int npaths = 0, cpaths = 0;
+	int i, j, ncache, npoints;
+
+	nvg__flattenPaths(ctx);
+	ncache = cache->npaths;
+	npoints = cache->npoints;
+
+	if (fill) {
+		if (ctx->params.edgeAntiAlias && state->shapeAntiAlias)
+			nvg__expandFill(ctx, ctx->fringeWidth, NVG_MITER, 2.4f);
+		else
+			nvg__expandFill(ctx, 0.0f, NVG_MITER, 2.4f);
+	} else {
+		// Calculate the convexity of the paths.
+		nvg__calculateJoins(ctx, 0.0f, NVG_MITER, 2.4f);
+	}
+	for (i = 0; i < ncache; i++) {
+		NVGpath* path = &cache->paths[i];
+		nvgoFlatPath* dst = nvgo__appendFlatPath(ctx, path, -1, &paths, &npaths, &cpaths);
+		if (dst == NULL) break;
+		if (fill) {
+			dst->fill = nvgo__copyVerts(path->fill, path->nfill);
+			dst->nfill = dst->fill != NULL ? path->nfill : 0;
+			dst->fringe = nvgo__copyVerts(path->stroke, path->nstroke);
+			dst->nfringe = dst->fringe != NULL ? path->nstroke : 0;
+		}
+	}
+
+	if (stroke && npaths == ncache) {
+		float scale = nvg__getAverageScale(state->xform);
+		float strokeWidth = nvg__clampf(state->strokeWidth * scale, 0.0f, 200.0f);
+		if (strokeWidth < ctx->fringeWidth)
+			strokeWidth = ctx->fringeWidth;
+
+		if (state->ndashes > 0) {
+			float dashes[NVG_MAX_DASHES];
+			for (i = 0; i < state->ndashes; i++)
+				dashes[i] = state->dashes[i] * scale;
+			// Dash the paths one by one to know the path of each dash.
+			for (i = 0; i < ncache; i++) {
+				NVGpath* base;
+				nvg__dashPaths(ctx, i, 1, dashes, state->ndashes, state->dashOffset * scale, state->lineCap);
+				base = cache->paths;
+				cache->paths += ncache;
+				cache->npaths -= ncache;
+				nvgo__expandStroke(ctx, strokeWidth);
+				for (j = 0; j < cache->npaths; j++) {
+					NVGpath* path = &cache->paths[j];
+					nvgoFlatPath* dst = nvgo__appendFlatPath(ctx, path, i, &paths, &npaths, &cpaths);
+					if (dst == NULL) break;
+					dst->stroke = nvgo__copyVerts(path->stroke, path->nstroke);
+					dst->nstroke = dst->stroke != NULL ? path->nstroke : 0;
+				}
+				cache->paths = base;
+				cache->npaths = ncache;
+				cache->npoints = npoints;
+			}
+		} else {
+			nvgo__expandStroke(ctx, strokeWidth);
+			for (i = 0; i < ncache; i++) {
+				NVGpath* path = &cache->paths[i];
+				paths[i].stroke = nvgo__copyVerts(path->stroke, path->nstroke);
+				paths[i].nstroke = paths[i].stroke != NULL ? path->nstroke : 0;
+			}
+		}
+	}
+
+	*count = npaths;
+	return paths;
+}
+
+void nvgoFreeFlatPaths(nvgoFlatPath* paths, int count)
+{
+	int i;
+	for (i = 0; i < count; i++) {
+		free(paths[i].points);
+		free(paths[i].fill);
+		free(paths[i].fringe);
+		free(paths[i].stroke);
+	}
+	free(paths);
+}
+
+// nvgoIsPointInPath returns 1 if (x,y) in frame coordinates is inside the
+// current path as filled by nvgFill(). nvgFill() fills the paths with the
+// nonzero rule after nvg__flattenPaths() applied the path windings.
+int nvgoIsPointInPath(NVGcontext* ctx, float x, float y)
+{
+	NVGpathCache* cache = ctx->cache;
+	int i, j, winding = 0;
+
+	nvg__flattenPaths(ctx);
+	for (i = 0; i < cache->npaths; i++) {
+		NVGpath* path = &cache->paths[i];
+		NVGpoint* pts = &cache->points[path->first];
+		NVGpoint* p0 = &pts[path->count-1];
+		for (j = 0; j < path->count; j++) {
+			NVGpoint* p1 = &pts[j];
+			float cross = (p1->x - p0->x) * (y - p0->y) - (x - p0->x) * (p1->y - p0->y);
+			if (p0->y <= y) {
+				if (p1->y > y && cross > 0.0f) winding++;
+			} else {
+				if (p1->y <= y && cross < 0.0f) winding--;
+			}
+			p0 = p1;
+		}
+	}
+	return winding != 0;
+}
+
+static int nvgo__ptInTriangle(float x, float y, const NVGvertex* a, const NVGvertex* b, const NVGvertex* c)
+{
+	float d0 = (b->x - a->x) * (y - a->y) - (x - a->x) * (b->y - a->y);
+	float d1 = (c->x - b->x) * (y - b->y) - (x - b->x) * (c->y - b->y);
+	float d2 = (a->x - c->x) * (y - c->y) - (x - c->x) * (a->y - c->y);
+	if (d0 == 0.0f && d1 == 0.0f && d2 == 0.0f)
+		return 0; // Degenerate triangle.
+	return (d0 >= 0.0f && d1 >= 0.0f && d2 >= 0.0f) || (d0 <= 0.0f && d1 <= 0.0f && d2 <= 0.0f);
+}
+
+// nvgoIsPointInStroke returns 1 if (x,y) in frame coordinates is inside the
+// stroke of the current path as drawn by nvgStroke(), not counting the
+// anti-aliasing fringe.
+int nvgoIsPointInStroke(NVGcontext* ctx, float x, float y)
+{
+	NVGstate* state = nvg__getState(ctx);
+	int shapeAntiAlias = state->shapeAntiAlias;
+	nvgoFlatPath* paths;
+	int i, j, n, inside = 0;
+
+	state->shapeAntiAlias = 0;
+	paths = nvgoFlattenPaths(ctx, 0, 1, &n);
+	state->shapeAntiAlias = shapeAntiAlias;
+	for (i = 0; i < n && !inside; i++) {
+		NVGvertex* verts = paths[i].stroke;
+		for (j = 0; j+2 < paths[i].nstroke && !inside; j++)
+			inside = nvgo__ptInTriangle(x, y, &verts[j], &verts[j+1], &verts[j+2]);
+	}
+	nvgoFreeFlatPaths(paths, n);
+	return inside;
+}
+*/
 import "C"
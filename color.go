@@ -0,0 +1,175 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+/*
+#include "nanovg/src/nanovg.h"
+*/
+import "C"
+import (
+	"image/color"
+	"unsafe"
+)
+
+// Color is a color with float RGBA components in [0,1], which are not
+// premultiplied by alpha. It implements color.Color, and can be used wherever
+// NanoVGo takes a color.Color without loss of precision.
+type Color struct {
+	r, g, b, a float32
+}
+
+// ColorModel converts any color.Color to a Color.
+var ColorModel = color.ModelFunc(func(c color.Color) color.Color {
+	return colorOf(c)
+})
+
+func (c Color) c() C.NVGcolor {
+	return *(*C.NVGcolor)(unsafe.Pointer(&c))
+}
+
+func goNVGColor(c C.NVGcolor) Color {
+	return *(*Color)(unsafe.Pointer(&c))
+}
+
+// colorOf converts c to a Color. The components of the color types of
+// image/color are converted exactly, other colors are converted from the
+// premultiplied components returned by their RGBA() method.
+func colorOf(c color.Color) Color {
+	switch c := c.(type) {
+	case Color:
+		return c
+	case color.NRGBA:
+		return Color{float32(c.R) / 0xff, float32(c.G) / 0xff, float32(c.B) / 0xff, float32(c.A) / 0xff}
+	case color.NRGBA64:
+		return Color{float32(c.R) / 0xffff, float32(c.G) / 0xffff, float32(c.B) / 0xffff, float32(c.A) / 0xffff}
+	case color.Gray:
+		var y = float32(c.Y) / 0xff
+		return Color{y, y, y, 1}
+	case color.Gray16:
+		var y = float32(c.Y) / 0xffff
+		return Color{y, y, y, 1}
+	}
+	// This includes color.RGBA and color.RGBA64, whose RGBA() returns their
+	// components.
+	return unpremultiplied(c.RGBA())
+}
+
+// unpremultiplied returns the color of premultiplied components in range
+// [0..0xffff]. The components are rounded up to 16 bits, so that RGBA()
+// returns the premultiplied components again.
+func unpremultiplied(r, g, b, a uint32) Color {
+	if a == 0 {
+		return Color{}
+	}
+	var unpremultiply = func(v uint32) float32 {
+		return float32((v*0xffff+a-1)/a) / 0xffff
+	}
+	return Color{unpremultiply(r), unpremultiply(g), unpremultiply(b), float32(a) / 0xffff}
+}
+
+// RGB returns a color value from red, green, blue values. Alpha will be set to
+// 255 (1.0f).
+func RGB(r, g, b uint8) Color {
+	return goNVGColor(C.nvgRGB(C.uchar(r), C.uchar(g), C.uchar(b)))
+}
+
+// RGBf returns a color value from red, green, blue values. Alpha will be set to
+// 1.0f.
+func RGBf(r, g, b float32) Color {
+	return goNVGColor(C.nvgRGBf(C.float(r), C.float(g), C.float(b)))
+}
+
+// RGBA returns a color value from red, green, blue and alpha values.
+func RGBA(r, g, b, a uint8) Color {
+	return goNVGColor(C.nvgRGBA(C.uchar(r), C.uchar(g), C.uchar(b), C.uchar(a)))
+}
+
+// RGBAf returns a color value from red, green, blue and alpha values.
+func RGBAf(r, g, b, a float32) Color {
+	return goNVGColor(C.nvgRGBAf(C.float(r), C.float(g), C.float(b), C.float(a)))
+}
+
+// HSL returns color value specified by hue, saturation and lightness. HSL
+// values are all in range [0..1], alpha will be set to 255.
+func HSL(h, s, l float32) Color {
+	return goNVGColor(C.nvgHSL(C.float(h), C.float(s), C.float(l)))
+}
+
+// HSLA returns color value specified by hue, saturation and lightness and
+// alpha. HSL values are all in range [0..1], alpha in range [0..255].
+func HSLA(h, s, l float32, a uint8) Color {
+	return goNVGColor(C.nvgHSLA(C.float(h), C.float(s), C.float(l), C.uchar(a)))
+}
+
+// R returns the red component of the color.
+func (c Color) R() float32 {
+	return c.r
+}
+
+// G returns the green component of the color.
+func (c Color) G() float32 {
+	return c.g
+}
+
+// B returns the blue component of the color.
+func (c Color) B() float32 {
+	return c.b
+}
+
+// A returns the alpha component of the color.
+func (c Color) A() float32 {
+	return c.a
+}
+
+// Lerp linearly interpolates from color c to c1, and returns the resulting
+// color value.
+func (c Color) Lerp(c1 Color, u float32) Color {
+	return goNVGColor(C.nvgLerpRGBA(c.c(), c1.c(), C.float(u)))
+}
+
+// WithAlpha returns the color with its transparency set to a, in range
+// [0..255].
+func (c Color) WithAlpha(a uint8) Color {
+	return goNVGColor(C.nvgTransRGBA(c.c(), C.uchar(a)))
+}
+
+// WithAlphaf returns the color with its transparency set to a, in range
+// [0..1].
+func (c Color) WithAlphaf(a float32) Color {
+	return goNVGColor(C.nvgTransRGBAf(c.c(), C.float(a)))
+}
+
+// RGBA implements color.Color. It returns the components premultiplied by
+// alpha in range [0..0xffff], which are rounded to 16 bits before being
+// premultiplied as color.NRGBA64 does.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	var to16 = func(f float32) uint32 {
+		return uint32(clampf(f, 0, 1)*0xffff + 0.5)
+	}
+	a = to16(c.a)
+	return to16(c.r) * a / 0xffff, to16(c.g) * a / 0xffff, to16(c.b) * a / 0xffff, a
+}
+
+// NRGBA returns the color as a color.NRGBA, rounding the components to 8 bits.
+func (c Color) NRGBA() color.NRGBA {
+	var to8 = func(f float32) uint8 {
+		return uint8(clampf(f, 0, 1)*255 + 0.5)
+	}
+	return color.NRGBA{to8(c.r), to8(c.g), to8(c.b), to8(c.a)}
+}
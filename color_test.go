@@ -0,0 +1,94 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"image/color"
+	"image/color/palette"
+	"testing"
+)
+
+// premulGray is a custom color.Color, which is converted through its RGBA()
+// method.
+type premulGray struct {
+	y, a uint16
+}
+
+func (c premulGray) RGBA() (r, g, b, a uint32) {
+	return uint32(c.y), uint32(c.y), uint32(c.y), uint32(c.a)
+}
+
+func TestColorModel(t *testing.T) {
+	var tests = []struct {
+		name  string
+		color color.Color
+		want  Color
+	}{
+		{"RGBA", color.RGBA{255, 128, 0, 255}, Color{1, 128.0 / 255, 0, 1}},
+		{"translucent RGBA", color.RGBA{100, 50, 0, 128}, Color{100.0 / 128, 50.0 / 128, 0, 128.0 / 255}},
+		{"transparent RGBA", color.RGBA{}, Color{}},
+		{"NRGBA", color.NRGBA{10, 20, 30, 40}, Color{10.0 / 255, 20.0 / 255, 30.0 / 255, 40.0 / 255}},
+		{"RGBA64", color.RGBA64{0x8000, 0x4000, 0, 0x8000}, Color{1, 0.5, 0, 0x8000 / float32(0xffff)}},
+		{"NRGBA64", color.NRGBA64{0xffff, 0, 0x1234, 0xffff}, Color{1, 0, 0x1234 / float32(0xffff), 1}},
+		{"Gray", color.Gray{51}, Color{0.2, 0.2, 0.2, 1}},
+		{"Gray16", color.Gray16{0x3333}, Color{0.2, 0.2, 0.2, 1}},
+		{"Alpha", color.Alpha{255}, Color{1, 1, 1, 1}},
+		{"palette", palette.WebSafe[1], Color{0, 0, 0.2, 1}},
+		{"custom", premulGray{0x4000, 0x8000}, Color{0.5, 0.5, 0.5, 0x8000 / float32(0xffff)}},
+		{"Color", RGBAf(0.1, 0.2, 0.3, 0.4), RGBAf(0.1, 0.2, 0.3, 0.4)},
+	}
+	for _, test := range tests {
+		var got, ok = ColorModel.Convert(test.color).(Color)
+		if !ok {
+			t.Errorf("%s: ColorModel.Convert() returned %T, want Color", test.name, got)
+			continue
+		}
+		if !colorNear(got, test.want) {
+			t.Errorf("%s: ColorModel.Convert(%v) = %v, want %v", test.name, test.color, got, test.want)
+		}
+		// Converting back with RGBA() gives the components of the original
+		// color.
+		var r0, g0, b0, a0 = test.color.RGBA()
+		var r1, g1, b1, a1 = got.RGBA()
+		if r0 != r1 || g0 != g1 || b0 != b1 || a0 != a1 {
+			t.Errorf("%s: RGBA() = %d %d %d %d, want %d %d %d %d", test.name, r1, g1, b1, a1, r0, g0, b0, a0)
+		}
+	}
+}
+
+func colorNear(c0, c1 Color) bool {
+	var near = func(a, b float32) bool {
+		return a-b < 1e-4 && b-a < 1e-4
+	}
+	return near(c0.r, c1.r) && near(c0.g, c1.g) && near(c0.b, c1.b) && near(c0.a, c1.a)
+}
+
+func TestColorNRGBA(t *testing.T) {
+	var tests = []color.NRGBA{
+		{0, 0, 0, 0},
+		{255, 128, 0, 255},
+		{10, 20, 30, 40},
+		{1, 254, 127, 128},
+	}
+	for _, want := range tests {
+		if got := colorOf(want).NRGBA(); got != want {
+			t.Errorf("NRGBA() of %v = %v", want, got)
+		}
+	}
+}
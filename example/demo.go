@@ -21,6 +21,7 @@ package main
 import (
 	"fmt"
 	"math"
+	"os"
 
 	"github.com/beta/nanovgo"
 )
@@ -137,8 +138,16 @@ func (data *demoData) render(vg *nanovgo.Context, mx, my, width, height float32,
 	vg.Restore()
 }
 
-func saveScreenShot(width, height int, premult bool, name string) {
-	// TODO: implement saveScreenShot.
+func saveScreenShot(vg *nanovgo.Context, width, height int, premult bool, name string) {
+	var f, err = os.Create(name)
+	if err != nil {
+		fmt.Printf("Failed to save screenshot: %v\n", err)
+		return
+	}
+	defer f.Close()
+	if err = vg.EncodePNG(f, 0, 0, width, height, premult); err != nil {
+		fmt.Printf("Failed to save screenshot: %v\n", err)
+	}
 }
 
 // Icon constants.
@@ -777,7 +786,7 @@ func drawThumbnails(vg *nanovgo.Context, x, y, width, height float32, images [12
 }
 
 func drawColorwheel(vg *nanovgo.Context, x, y, width, height float32, t float64) {
-	var r0, r1, ax, ay, bx, by, cx, cy, aeps, r float32
+	var r0, r1, ax, ay, bx, by, cx, cy, r float32
 	var hue = float32(math.Sin(t * 0.12))
 
 	vg.Save()
@@ -790,23 +799,19 @@ func drawColorwheel(vg *nanovgo.Context, x, y, width, height float32, t float64)
 		r1 = height*0.5 - 5.0
 	}
 	r0 = r1 - 20.0
-	aeps = 0.5 / r1 // Half a pixel arc length in radians (2pi cancels out).
 
-	for i := 0; i < 6; i++ {
-		var a0 = float32(i)/6.0*float32(math.Pi)*2.0 - aeps
-		var a1 = float32(i+1.0)/6.0*float32(math.Pi)*2.0 + aeps
-		vg.BeginPath()
-		vg.Arc(cx, cy, r0, a0, a1, nanovgo.CW)
-		vg.Arc(cx, cy, r1, a1, a0, nanovgo.CCW)
-		vg.ClosePath()
-		ax = cx + float32(math.Cos(float64(a0)))*(r0+r1)*0.5
-		ay = cy + float32(math.Sin(float64(a0)))*(r0+r1)*0.5
-		bx = cx + float32(math.Cos(float64(a1)))*(r0+r1)*0.5
-		by = cy + float32(math.Sin(float64(a1)))*(r0+r1)*0.5
-		var paint = vg.LinearGradient(ax, ay, bx, by, nanovgo.HSLA(a0/(math.Pi*2), 1.0, 0.55, 255), nanovgo.HSLA(a1/(math.Pi*2), 1.0, 0.55, 255))
-		vg.FillPaint(paint)
-		vg.Fill()
+	// The hue ring is filled with a conic gradient through six hues.
+	var stops = make([]nanovgo.GradientStop, 7)
+	for i := range stops {
+		var u = float32(i) / 6.0
+		stops[i] = nanovgo.GradientStop{Offset: u, Color: nanovgo.HSLA(u, 1.0, 0.55, 255)}
 	}
+	vg.BeginPath()
+	vg.Circle(cx, cy, r1)
+	vg.Circle(cx, cy, r0)
+	vg.PathWinding(nanovgo.Hole)
+	vg.FillPaint(vg.ConicGradient(cx, cy, 0, stops...))
+	vg.Fill()
 
 	vg.BeginPath()
 	vg.Circle(cx, cy, r0-0.5)
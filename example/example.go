@@ -149,7 +149,7 @@ func main() {
 
 		if screenshot {
 			screenshot = false
-			saveScreenShot(fbWidth, fbHeight, premult, "dump.png")
+			saveScreenShot(vg, fbWidth, fbHeight, premult, "dump.png")
 		}
 
 		win.SwapBuffers()
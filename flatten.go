@@ -0,0 +1,146 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+/*
+#include "nanovg/src/nanovg.h"
+
+// See c.go.
+typedef struct nvgoFlatPath {
+	float* points;
+	int npoints;
+	int closed, winding, convex;
+	NVGvertex* fill;
+	int nfill;
+	NVGvertex* fringe;
+	int nfringe;
+	NVGvertex* stroke;
+	int nstroke;
+	int parent;
+} nvgoFlatPath;
+
+nvgoFlatPath* nvgoFlattenPaths(NVGcontext* ctx, int fill, int stroke, int* count);
+void nvgoFreeFlatPaths(nvgoFlatPath* paths, int count);
+int nvgoIsPointInPath(NVGcontext* ctx, float x, float y);
+int nvgoIsPointInStroke(NVGcontext* ctx, float x, float y);
+*/
+import "C"
+import "unsafe"
+
+// FlatPath is a sub-path of the current path as flattened by NanoVG. The
+// points are in frame coordinates, that is the current transform is applied,
+// curves are subdivided into line segments, and the winding of the path is
+// changed to match its PathWinding().
+type FlatPath struct {
+	Points  [][2]float32
+	Closed  bool
+	Winding Winding
+	Convex  bool
+	// Fill is a triangle fan covering the path, and Fringe is the triangle
+	// strip of its anti-aliasing fringe, as Fill() tessellates the path.
+	Fill   []Vertex
+	Fringe []Vertex
+	// Stroke is the triangle strip of the stroke with the current stroke
+	// style, as Stroke() tessellates the path. Stroke is empty for dashed
+	// strokes, whose dashes are in Dashes instead.
+	Stroke []Vertex
+	// Dashes are the dashes of a dashed stroke. Each dash is an open path
+	// with Points and Stroke set.
+	Dashes []FlatPath
+}
+
+// TessellateFlags selects the vertices computed by TessellateCurrentPath().
+type TessellateFlags int
+
+// Tessellate flags.
+const (
+	// TessellateFill computes the Fill and Fringe vertices.
+	TessellateFill TessellateFlags = 1 << iota
+	// TessellateStroke computes the Stroke vertices and the Dashes.
+	TessellateStroke
+)
+
+// FlattenCurrentPath returns the sub-paths of the current path flattened as
+// they are filled or stroked, without the vertices. The current path is
+// kept. This is useful for hit testing and exporting geometry.
+func (ctx *Context) FlattenCurrentPath() []FlatPath {
+	return ctx.TessellateCurrentPath(0)
+}
+
+// TessellateCurrentPath returns the sub-paths of the current path like
+// FlattenCurrentPath(), with the vertices selected by flags. The stroke
+// vertices use the current stroke width, line cap, line join, miter limit
+// and dash pattern. The vertices are the same as handed over to the
+// renderer, so they are useful for debugging tessellation artifacts.
+func (ctx *Context) TessellateCurrentPath(flags TessellateFlags) []FlatPath {
+	var fill, stroke, n C.int
+	if flags&TessellateFill != 0 {
+		fill = 1
+	}
+	if flags&TessellateStroke != 0 {
+		stroke = 1
+	}
+	var cPaths = C.nvgoFlattenPaths(ctx.c(), fill, stroke, &n)
+	if cPaths == nil {
+		return nil
+	}
+	defer C.nvgoFreeFlatPaths(cPaths, n)
+
+	var paths []FlatPath
+	for _, p := range unsafe.Slice(cPaths, int(n)) {
+		var path = FlatPath{
+			Closed:  p.closed != 0,
+			Winding: Winding(p.winding),
+			Convex:  p.convex != 0,
+			Fill:    goVertices(p.fill, p.nfill),
+			Fringe:  goVertices(p.fringe, p.nfringe),
+			Stroke:  goVertices(p.stroke, p.nstroke),
+		}
+		if p.npoints > 0 {
+			var pts = unsafe.Slice(p.points, int(p.npoints)*2)
+			path.Points = make([][2]float32, int(p.npoints))
+			for i := range path.Points {
+				path.Points[i] = [2]float32{float32(pts[i*2]), float32(pts[i*2+1])}
+			}
+		}
+		if p.parent >= 0 {
+			var parent = &paths[p.parent]
+			parent.Dashes = append(parent.Dashes, path)
+		} else {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// IsPointInPath returns true if the point (x,y) is inside the current path as
+// filled by Fill(). Like the current path, the point is in frame coordinates,
+// so it is not transformed by the current transform. Sub-paths are closed,
+// and holes set with PathWinding() are excluded.
+func (ctx *Context) IsPointInPath(x, y float32) bool {
+	return C.nvgoIsPointInPath(ctx.c(), C.float(x), C.float(y)) != 0
+}
+
+// IsPointInStroke returns true if the point (x,y) is inside the stroke of the
+// current path as drawn by Stroke() with the current stroke width, line cap,
+// line join, miter limit and dash pattern. Like the current path, the point is
+// in frame coordinates, so it is not transformed by the current transform.
+func (ctx *Context) IsPointInStroke(x, y float32) bool {
+	return C.nvgoIsPointInStroke(ctx.c(), C.float(x), C.float(y)) != 0
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"math"
+	"testing"
+)
+
+// flatPathArea returns the signed area of the points of path, which is
+// negative for Solid paths, as y points down.
+func flatPathArea(path FlatPath) float32 {
+	var area float32
+	for i, p0 := range path.Points {
+		var p1 = path.Points[(i+1)%len(path.Points)]
+		area += p0[0]*p1[1] - p1[0]*p0[1]
+	}
+	return area / 2
+}
+
+func TestFlattenCurrentPath(t *testing.T) {
+	type flatTest struct {
+		npoints int
+		closed  bool
+		winding Winding
+		bounds  [4]float32
+	}
+	var tests = []struct {
+		name      string
+		transform func(ctx *Context)
+		want      []flatTest
+	}{
+		{"Identity", func(ctx *Context) {}, []flatTest{
+			{4, true, Solid, [4]float32{10, 10, 90, 90}},
+			{4, true, Hole, [4]float32{30, 30, 70, 70}},
+			{3, false, Solid, [4]float32{10, 95, 90, 99}},
+		}},
+		// The points are in frame coordinates.
+		{"Transformed", func(ctx *Context) {
+			ctx.Translate(100, 50)
+			ctx.Scale(2, 0.5)
+		}, []flatTest{
+			{4, true, Solid, [4]float32{120, 55, 280, 95}},
+			{4, true, Hole, [4]float32{160, 65, 240, 85}},
+			{3, false, Solid, [4]float32{120, 97.5, 280, 99.5}},
+		}},
+		// Mirroring keeps the enforced windings.
+		{"Mirrored", func(ctx *Context) {
+			ctx.Scale(-1, 1)
+		}, []flatTest{
+			{4, true, Solid, [4]float32{-90, 10, -10, 90}},
+			{4, true, Hole, [4]float32{-70, 30, -30, 70}},
+			{3, false, Solid, [4]float32{-90, 95, -10, 99}},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var paths []FlatPath
+			renderSoftware(t, Antialias, func(ctx *Context) {
+				test.transform(ctx)
+				ctx.BeginPath()
+				ctx.Rect(10, 10, 80, 80)
+				ctx.Rect(30, 30, 40, 40)
+				ctx.PathWinding(Hole)
+				ctx.MoveTo(10, 99)
+				ctx.LineTo(50, 95)
+				ctx.LineTo(90, 99)
+				paths = ctx.FlattenCurrentPath()
+			})
+			if len(paths) != len(test.want) {
+				t.Fatalf("FlattenCurrentPath() returned %d paths, want %d", len(paths), len(test.want))
+			}
+			for i, want := range test.want {
+				var path = paths[i]
+				if len(path.Points) != want.npoints || path.Closed != want.closed || path.Winding != want.winding {
+					t.Errorf("path %d has %d points, closed %v, winding %v, want %d points, closed %v, winding %v", i,
+						len(path.Points), path.Closed, path.Winding, want.npoints, want.closed, want.winding)
+					continue
+				}
+				if want.closed {
+					if area := flatPathArea(path); (area < 0) != (want.winding == Solid) {
+						t.Errorf("path %d has area %v with winding %v", i, area, want.winding)
+					}
+				}
+				var bounds = [4]float32{math.MaxFloat32, math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+				for _, pt := range path.Points {
+					bounds[0], bounds[1] = minf(bounds[0], pt[0]), minf(bounds[1], pt[1])
+					bounds[2], bounds[3] = maxf(bounds[2], pt[0]), maxf(bounds[3], pt[1])
+				}
+				if bounds != want.bounds {
+					t.Errorf("path %d has bounds %v, want %v", i, bounds, want.bounds)
+				}
+				if path.Fill != nil || path.Fringe != nil || path.Stroke != nil {
+					t.Errorf("path %d has vertices", i)
+				}
+			}
+		})
+	}
+}
+
+type hitTest struct {
+	x, y float32
+	want bool
+}
+
+func TestIsPointInPath(t *testing.T) {
+	var tests = []struct {
+		name  string
+		build func(ctx *Context)
+		hits  []hitTest
+	}{
+		{"Ring", func(ctx *Context) {
+			ctx.Circle(50, 50, 40)
+			ctx.Circle(50, 50, 20)
+			ctx.PathWinding(Hole)
+		}, []hitTest{{50, 20, true}, {50, 50, false}, {5, 5, false}, {50, 95, false}}},
+		// Without a hole winding the inner circle is filled too.
+		{"NestedSolid", func(ctx *Context) {
+			ctx.Circle(50, 50, 40)
+			ctx.Circle(50, 50, 20)
+		}, []hitTest{{50, 20, true}, {50, 50, true}}},
+		{"EvenOdd", func(ctx *Context) {
+			var path, _ = ParseSVGPath("M0 0H100V100H0Z M20 20H80V80H20Z M40 40H60V60H40Z")
+			ctx.AppendPath(svgFillPath(path, true))
+		}, []hitTest{{10, 10, true}, {30, 30, false}, {50, 50, true}}},
+		// Open sub-paths are closed.
+		{"Open", func(ctx *Context) {
+			ctx.MoveTo(10, 10)
+			ctx.LineTo(90, 10)
+			ctx.LineTo(50, 90)
+		}, []hitTest{{50, 30, true}, {20, 60, false}}},
+		// The point is not transformed.
+		{"Transformed", func(ctx *Context) {
+			ctx.Translate(50, 0)
+			ctx.Rect(0, 0, 20, 20)
+		}, []hitTest{{60, 10, true}, {10, 10, false}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			renderSoftware(t, Antialias, func(ctx *Context) {
+				ctx.BeginPath()
+				test.build(ctx)
+				for _, hit := range test.hits {
+					if got := ctx.IsPointInPath(hit.x, hit.y); got != hit.want {
+						t.Errorf("IsPointInPath(%v, %v) = %v, want %v", hit.x, hit.y, got, hit.want)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestIsPointInStroke(t *testing.T) {
+	var line = func(ctx *Context) {
+		ctx.MoveTo(20, 50)
+		ctx.LineTo(80, 50)
+		ctx.StrokeWidth(10)
+	}
+	var corner = func(ctx *Context) {
+		ctx.MoveTo(20, 80)
+		ctx.LineTo(20, 20)
+		ctx.LineTo(80, 20)
+		ctx.StrokeWidth(10)
+	}
+	var tests = []struct {
+		name  string
+		build func(ctx *Context)
+		hits  []hitTest
+	}{
+		{"ButtCap", line, []hitTest{{50, 50, true}, {50, 54, true}, {50, 56, false}, {21, 50, true}, {18, 50, false}}},
+		{"SquareCap", func(ctx *Context) {
+			line(ctx)
+			ctx.LineCap(Square)
+		}, []hitTest{{18, 50, true}, {16, 54, true}, {14, 50, false}}},
+		{"RoundCap", func(ctx *Context) {
+			line(ctx)
+			ctx.LineCap(RoundCap)
+		}, []hitTest{{16, 50, true}, {17, 54.5, false}, {14, 50, false}}},
+		{"MiterJoin", corner, []hitTest{{20, 50, true}, {16, 16, true}, {50, 24, true}, {30, 30, false}}},
+		{"BevelJoin", func(ctx *Context) {
+			corner(ctx)
+			ctx.LineJoin(Bevel)
+		}, []hitTest{{19, 19, true}, {16, 16, false}}},
+		{"RoundJoin", func(ctx *Context) {
+			corner(ctx)
+			ctx.LineJoin(RoundJoin)
+		}, []hitTest{{17, 17, true}, {16, 16, false}}},
+		{"Dashes", func(ctx *Context) {
+			ctx.MoveTo(10, 50)
+			ctx.LineTo(90, 50)
+			ctx.StrokeWidth(4)
+			ctx.StrokeDash([]float32{10, 10}, 0)
+		}, []hitTest{{15, 50, true}, {25, 50, false}, {35, 50, true}, {35, 53, false}}},
+		// The stroke width is transformed, the point is not.
+		{"Transformed", func(ctx *Context) {
+			ctx.Translate(0, 20)
+			ctx.Scale(2, 2)
+			ctx.MoveTo(10, 15)
+			ctx.LineTo(40, 15)
+			ctx.StrokeWidth(4)
+		}, []hitTest{{50, 50, true}, {50, 53, true}, {50, 55, false}, {50, 15, false}}},
+		{"Closed", func(ctx *Context) {
+			ctx.Rect(20, 20, 60, 60)
+			ctx.StrokeWidth(4)
+		}, []hitTest{{20, 50, true}, {80, 50, true}, {50, 50, false}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			renderSoftware(t, Antialias, func(ctx *Context) {
+				ctx.BeginPath()
+				test.build(ctx)
+				for _, hit := range test.hits {
+					if got := ctx.IsPointInStroke(hit.x, hit.y); got != hit.want {
+						t.Errorf("IsPointInStroke(%v, %v) = %v, want %v", hit.x, hit.y, got, hit.want)
+					}
+				}
+			})
+		})
+	}
+}
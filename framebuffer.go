@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+/*
+#include <stdint.h>
+#include "nanovg/src/nanovg.h"
+
+// The GL backends compile the implementation of nanovg_gl_utils.h, only the
+// declarations are needed here.
+typedef unsigned int GLuint;
+#include "nanovg/src/nanovg_gl_utils.h"
+
+int nvgoIsInternal(NVGcontext* ctx);
+*/
+import "C"
+
+// Framebuffer is an OpenGL framebuffer object to render into offscreen. Its
+// color buffer is an image, which can be drawn with Context.ImagePattern()
+// once rendering into the framebuffer is done.
+//
+//     var fb = ctx.CreateFramebuffer(width, height, 0)
+//     fb.Bind()
+//     gl.Viewport(0, 0, width, height)
+//     ctx.BeginFrame(width, height, 1)
+//     // Draw the offscreen content with ctx.
+//     ctx.EndFrame()
+//     ctx.BindDefaultFramebuffer()
+//     // Draw fb.Image() with ctx.
+type Framebuffer struct {
+	cFramebuffer *C.NVGLUframebuffer
+	image        *Image
+}
+
+// CreateFramebuffer creates a framebuffer of size width*height, the image
+// flags are used for its image, which also has ImageFlipy and
+// ImagePremultiplied set. Returns nil if the framebuffer cannot be created,
+// i.e. when framebuffer objects are not supported, which is the case for the
+// OpenGL 2 backend on platforms other than macOS, or when ctx is not rendered
+// by OpenGL.
+func (ctx *Context) CreateFramebuffer(width, height int, imageFlags ImageFlag) *Framebuffer {
+	if C.nvgoIsInternal(ctx.c()) != 0 {
+		return nil
+	}
+	var fb = C.nvgluCreateFramebuffer(ctx.c(), C.int(width), C.int(height), C.int(imageFlags))
+	if fb == nil {
+		return nil
+	}
+	return &Framebuffer{
+		cFramebuffer: fb,
+		image: &Image{
+			cImage: fb.image,
+			ctx:    ctx,
+		},
+	}
+}
+
+// Bind binds the framebuffer, the following frames are rendered into it.
+func (fb *Framebuffer) Bind() {
+	C.nvgluBindFramebuffer(fb.cFramebuffer)
+}
+
+// BindDefaultFramebuffer binds the framebuffer which was bound when ctx was
+// created, which usually is its window, or the offscreen framebuffer of a
+// headless context. It does nothing if ctx is not rendered by OpenGL.
+func (ctx *Context) BindDefaultFramebuffer() {
+	if C.nvgoIsInternal(ctx.c()) != 0 {
+		return
+	}
+	C.nvgluBindDefaultFramebuffer(ctx.c())
+}
+
+// Image returns the image of the framebuffer. The image is deleted together
+// with the framebuffer, and must not be deleted on its own.
+func (fb *Framebuffer) Image() *Image {
+	return fb.image
+}
+
+// Delete deletes the framebuffer and its image.
+func (fb *Framebuffer) Delete() {
+	C.nvgluDeleteFramebuffer(fb.cFramebuffer)
+	fb.cFramebuffer = nil
+}
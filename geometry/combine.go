@@ -0,0 +1,484 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package geometry
+
+import (
+	"math"
+	"sort"
+
+	"github.com/beta/nanovgo"
+)
+
+// The polygons are combined in a planar graph. The edges of both polygons are
+// split where they touch or cross each other, so the pieces only meet at
+// their ends. Each piece is then classified by the winding numbers of the
+// polygons on its two sides, and the pieces separating the inside of the
+// result from the outside are linked into the contours of the result.
+
+// point is a vertex of the graph. The coordinates have float32 precision, so
+// vertices are looked up by value.
+type point struct {
+	x, y float64
+}
+
+// edge is an edge of an input polygon, going from vertex from to vertex to.
+type edge struct {
+	from, to int
+	// poly is 0 for the first polygon and 1 for the second.
+	poly int
+	// splits are the vertices on the edge, which is split there.
+	splits []edgeSplit
+}
+
+type edgeSplit struct {
+	// t is the position of the vertex along the edge, from 0 to 1.
+	t float64
+	v int
+}
+
+// segment is a piece of edges between the vertices lo and hi, where lo < hi.
+type segment struct {
+	lo, hi int
+	// count is the net number of edges of each polygon running from lo to hi,
+	// that is their contribution to winding numbers.
+	count [2]int
+	// keep is set if the segment separates the inside of the result from the
+	// outside. forward is set if the inside is on the right of lo to hi.
+	keep, forward bool
+}
+
+type graph struct {
+	points   []point
+	index    map[point]int
+	edges    []edge
+	segments []segment
+	// eps is the distance under which a vertex is considered to be on an edge.
+	eps float64
+}
+
+func newGraph(a, b []nanovgo.FlatPath) *graph {
+	var g = &graph{index: make(map[point]int)}
+	var scale = 1.0
+	for poly, paths := range [2][]nanovgo.FlatPath{a, b} {
+		for _, path := range paths {
+			if len(path.Points) < 3 {
+				continue
+			}
+			var first = g.vertex(float64(path.Points[0][0]), float64(path.Points[0][1]))
+			var prev = first
+			for i := range path.Points {
+				var pt = path.Points[(i+1)%len(path.Points)]
+				scale = math.Max(scale, math.Max(math.Abs(float64(pt[0])), math.Abs(float64(pt[1]))))
+				var v = first
+				if i+1 < len(path.Points) {
+					v = g.vertex(float64(pt[0]), float64(pt[1]))
+				}
+				if v != prev {
+					g.edges = append(g.edges, edge{from: prev, to: v, poly: poly})
+				}
+				prev = v
+			}
+		}
+	}
+	g.eps = scale * 1e-6
+	return g
+}
+
+// vertex returns the vertex at (x,y), which is rounded to float32 precision,
+// adding it if it does not exist.
+func (g *graph) vertex(x, y float64) int {
+	var pt = point{float64(float32(x)), float64(float32(y))}
+	if v, ok := g.index[pt]; ok {
+		return v
+	}
+	g.points = append(g.points, pt)
+	g.index[pt] = len(g.points) - 1
+	return len(g.points) - 1
+}
+
+// split splits the edges where they touch or cross each other, and merges
+// the pieces into segments.
+func (g *graph) split() {
+	var order = make([]int, len(g.edges))
+	for i := range order {
+		order[i] = i
+	}
+	var minX = func(e *edge) float64 {
+		return math.Min(g.points[e.from].x, g.points[e.to].x)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return minX(&g.edges[order[i]]) < minX(&g.edges[order[j]])
+	})
+	for i, ei := range order {
+		var e = &g.edges[ei]
+		var a, b = g.points[e.from], g.points[e.to]
+		var maxX = math.Max(a.x, b.x) + g.eps
+		var minY, maxY = math.Min(a.y, b.y) - g.eps, math.Max(a.y, b.y) + g.eps
+		for _, fi := range order[i+1:] {
+			var f = &g.edges[fi]
+			if minX(f) > maxX {
+				break
+			}
+			var c, d = g.points[f.from], g.points[f.to]
+			if math.Max(c.y, d.y) < minY || math.Min(c.y, d.y) > maxY {
+				continue
+			}
+			g.intersect(e, f)
+		}
+	}
+
+	var segments = make(map[[2]int]int)
+	for i := range g.edges {
+		var e = &g.edges[i]
+		sort.Slice(e.splits, func(i, j int) bool {
+			return e.splits[i].t < e.splits[j].t
+		})
+		var prev = e.from
+		for j := 0; j <= len(e.splits); j++ {
+			var v = e.to
+			if j < len(e.splits) {
+				v = e.splits[j].v
+			}
+			if v == prev {
+				continue
+			}
+			var key, dir = [2]int{prev, v}, 1
+			if prev > v {
+				key, dir = [2]int{v, prev}, -1
+			}
+			var s, ok = segments[key]
+			if !ok {
+				s = len(g.segments)
+				segments[key] = s
+				g.segments = append(g.segments, segment{lo: key[0], hi: key[1]})
+			}
+			g.segments[s].count[e.poly] += dir
+			prev = v
+		}
+	}
+}
+
+// intersect adds the vertices where the edges e and f touch or cross to their
+// splits.
+func (g *graph) intersect(e, f *edge) {
+	// Ends of one edge on the other edge.
+	g.splitAt(e, f.from)
+	g.splitAt(e, f.to)
+	g.splitAt(f, e.from)
+	g.splitAt(f, e.to)
+
+	// Proper crossing, the ends of each edge are on both sides of the other.
+	var a, b = g.points[e.from], g.points[e.to]
+	var c, d = g.points[f.from], g.points[f.to]
+	var sa, sb = g.side(c, d, a), g.side(c, d, b)
+	var sc, sd = g.side(a, b, c), g.side(a, b, d)
+	if !(sa > g.eps && sb < -g.eps || sa < -g.eps && sb > g.eps) ||
+		!(sc > g.eps && sd < -g.eps || sc < -g.eps && sd > g.eps) {
+		return
+	}
+	var t = sa / (sa - sb)
+	var v = g.vertex(a.x+t*(b.x-a.x), a.y+t*(b.y-a.y))
+	// The crossing is rounded to float32, which can move it off the edges
+	// when they are nearly parallel. It is only used if it is still on both
+	// edges, and it is placed along them by its rounded position, so the
+	// splits of each edge stay in order.
+	var te, okE = g.onEdge(e, v)
+	var tf, okF = g.onEdge(f, v)
+	if !okE || !okF {
+		return
+	}
+	e.splits = append(e.splits, edgeSplit{te, v})
+	f.splits = append(f.splits, edgeSplit{tf, v})
+}
+
+// splitAt adds vertex v to the splits of edge e if v is on the edge between
+// its ends.
+func (g *graph) splitAt(e *edge, v int) {
+	if t, ok := g.onEdge(e, v); ok {
+		e.splits = append(e.splits, edgeSplit{t, v})
+	}
+}
+
+// onEdge returns the position of vertex v along edge e, and whether v is on
+// the edge between its ends.
+func (g *graph) onEdge(e *edge, v int) (float64, bool) {
+	if v == e.from || v == e.to {
+		return 0, false
+	}
+	var a, b, p = g.points[e.from], g.points[e.to], g.points[v]
+	var dx, dy = b.x - a.x, b.y - a.y
+	var t = ((p.x-a.x)*dx + (p.y-a.y)*dy) / (dx*dx + dy*dy)
+	if t <= 0 || t >= 1 || math.Abs(g.side(a, b, p)) > g.eps {
+		return 0, false
+	}
+	return t, true
+}
+
+// side returns the signed distance of p from the line through a and b.
+func (g *graph) side(a, b, p point) float64 {
+	var dx, dy = b.x - a.x, b.y - a.y
+	return (dx*(p.y-a.y) - dy*(p.x-a.x)) / math.Hypot(dx, dy)
+}
+
+// classify sets keep and forward of the segments for the operation op.
+func (g *graph) classify(op Op) {
+	// The segments which contribute to winding numbers, in strips for
+	// casting rays through them.
+	var active []int
+	for i, s := range g.segments {
+		if s.count != [2]int{} {
+			active = append(active, i)
+		}
+	}
+	var columns = g.strips(active, func(p point) float64 { return p.x })
+	var rows = g.strips(active, func(p point) float64 { return p.y })
+
+	for i := range g.segments {
+		var s = &g.segments[i]
+		var p, q = g.points[s.lo], g.points[s.hi]
+		var m = point{(p.x + q.x) * 0.5, (p.y + q.y) * 0.5}
+		// Cast a ray from the middle of the segment across the other
+		// segments, to the top of the screen for mostly horizontal segments
+		// and to the right for mostly vertical ones. The winding numbers far
+		// from the segment are those of the side the ray goes into, and the
+		// ones of the near side include the segment itself.
+		var horizontal = math.Abs(q.x-p.x) >= math.Abs(q.y-p.y)
+		var far [2]int
+		if horizontal {
+			for _, j := range columns.at(m.x) {
+				var t = &g.segments[j]
+				var tp, tq = g.points[t.lo], g.points[t.hi]
+				if j == i || (tp.x <= m.x) == (tq.x <= m.x) {
+					continue
+				}
+				if tp.y+(m.x-tp.x)*(tq.y-tp.y)/(tq.x-tp.x) > m.y {
+					var dir = crossDir(tq.x < tp.x)
+					far[0] += dir * t.count[0]
+					far[1] += dir * t.count[1]
+				}
+			}
+		} else {
+			for _, j := range rows.at(m.y) {
+				var t = &g.segments[j]
+				var tp, tq = g.points[t.lo], g.points[t.hi]
+				if j == i || (tp.y <= m.y) == (tq.y <= m.y) {
+					continue
+				}
+				if tp.x+(m.y-tp.y)*(tq.x-tp.x)/(tq.y-tp.y) > m.x {
+					var dir = crossDir(tq.y > tp.y)
+					far[0] += dir * t.count[0]
+					far[1] += dir * t.count[1]
+				}
+			}
+		}
+		var dir int
+		if horizontal {
+			dir = crossDir(q.x < p.x)
+		} else {
+			dir = crossDir(q.y > p.y)
+		}
+		var near = [2]int{far[0] + dir*s.count[0], far[1] + dir*s.count[1]}
+		var inFar = op.contains(far[0] != 0, far[1] != 0)
+		var inNear = op.contains(near[0] != 0, near[1] != 0)
+		if inFar == inNear {
+			continue
+		}
+		// Going from lo to hi, the side the ray goes into is on the right if
+		// the ray crosses the segment from right to left, which is counted
+		// as +1.
+		s.keep = true
+		s.forward = (dir > 0) == inFar
+	}
+}
+
+// strips are the segments of a graph in strips of equal width along an axis.
+type strips struct {
+	min, width float64
+	segments   [][]int
+}
+
+// strips puts the segments into strips along the axis of the coordinate
+// returned by coord.
+func (g *graph) strips(segments []int, coord func(p point) float64) *strips {
+	var st = &strips{min: math.Inf(1), width: 1}
+	var max = math.Inf(-1)
+	for _, i := range segments {
+		var s = &g.segments[i]
+		st.min = math.Min(st.min, math.Min(coord(g.points[s.lo]), coord(g.points[s.hi])))
+		max = math.Max(max, math.Max(coord(g.points[s.lo]), coord(g.points[s.hi])))
+	}
+	var n = int(math.Sqrt(float64(len(segments)))) + 1
+	if max > st.min {
+		st.width = (max - st.min) / float64(n)
+	}
+	st.segments = make([][]int, n)
+	for _, i := range segments {
+		var s = &g.segments[i]
+		var a, b = coord(g.points[s.lo]), coord(g.points[s.hi])
+		for j := st.index(math.Min(a, b)); j <= st.index(math.Max(a, b)); j++ {
+			st.segments[j] = append(st.segments[j], i)
+		}
+	}
+	return st
+}
+
+func (st *strips) index(v float64) int {
+	var i = int((v - st.min) / st.width)
+	if i < 0 {
+		return 0
+	}
+	if i >= len(st.segments) {
+		return len(st.segments) - 1
+	}
+	return i
+}
+
+// at returns the segments which may cross the line at v.
+func (st *strips) at(v float64) []int {
+	if len(st.segments) == 0 || v < st.min {
+		return nil
+	}
+	return st.segments[st.index(v)]
+}
+
+// crossDir returns the contribution of a segment crossed by a ray to winding
+// numbers, which is +1 for segments crossing the ray counter-clockwise
+// around its origin, with y pointing up.
+func crossDir(ccw bool) int {
+	if ccw {
+		return 1
+	}
+	return -1
+}
+
+// contours links the kept segments into closed paths.
+func (g *graph) contours() []nanovgo.FlatPath {
+	type link struct {
+		from, to int
+		used     bool
+	}
+	var links []link
+	var out = make(map[int][]int)
+	for _, s := range g.segments {
+		if !s.keep {
+			continue
+		}
+		var l = link{from: s.lo, to: s.hi}
+		if !s.forward {
+			l.from, l.to = s.hi, s.lo
+		}
+		out[l.from] = append(out[l.from], len(links))
+		links = append(links, l)
+	}
+	// next returns the link following l around the face on the right of l,
+	// which is the link turning most to the right.
+	var next = func(l int) int {
+		var a, b = g.points[links[l].from], g.points[links[l].to]
+		var dx, dy = b.x - a.x, b.y - a.y
+		var best, bestAngle = -1, math.Inf(1)
+		for _, k := range out[links[l].to] {
+			var c = g.points[links[k].to]
+			var ex, ey = c.x - b.x, c.y - b.y
+			var angle = math.Atan2(dx*ey-dy*ex, dx*ex+dy*ey)
+			if angle == -math.Pi {
+				angle = math.Pi
+			}
+			if angle < bestAngle {
+				best, bestAngle = k, angle
+			}
+		}
+		return best
+	}
+
+	var paths []nanovgo.FlatPath
+	for first := range links {
+		if links[first].used {
+			continue
+		}
+		var loop []int
+		var closed bool
+		for l := first; l >= 0 && !links[l].used; l = next(l) {
+			links[l].used = true
+			loop = append(loop, links[l].from)
+			if next(l) == first {
+				closed = true
+			}
+		}
+		if !closed {
+			continue
+		}
+		if path, ok := g.contour(loop); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// contour returns the closed path through the vertices of loop, without the
+// vertices in the middle of straight lines. The path is dropped if it has no
+// area.
+func (g *graph) contour(loop []int) (nanovgo.FlatPath, bool) {
+	var pts []point
+	for _, v := range loop {
+		pts = append(pts, g.points[v])
+		for len(pts) >= 3 && g.straight(pts[len(pts)-3], pts[len(pts)-2], pts[len(pts)-1]) {
+			pts = append(pts[:len(pts)-2], pts[len(pts)-1])
+		}
+	}
+	for len(pts) >= 3 {
+		var n = len(pts)
+		if g.straight(pts[n-2], pts[n-1], pts[0]) {
+			pts = pts[:n-1]
+		} else if g.straight(pts[n-1], pts[0], pts[1]) {
+			pts = pts[1:]
+		} else {
+			break
+		}
+	}
+	if len(pts) < 3 {
+		return nanovgo.FlatPath{}, false
+	}
+
+	var area, perimeter float64
+	var path = nanovgo.FlatPath{Closed: true}
+	for i, p := range pts {
+		var q = pts[(i+1)%len(pts)]
+		area += p.x*q.y - q.x*p.y
+		perimeter += math.Hypot(q.x-p.x, q.y-p.y)
+		path.Points = append(path.Points, [2]float32{float32(p.x), float32(p.y)})
+	}
+	if math.Abs(area) <= g.eps*perimeter {
+		return nanovgo.FlatPath{}, false
+	}
+	// The inside is on the right, so outlines are counter-clockwise on
+	// screen, where y points down, and have a negative area.
+	path.Winding = nanovgo.CCW
+	if area > 0 {
+		path.Winding = nanovgo.CW
+	}
+	return path, true
+}
+
+// straight returns whether b is on the straight line from a to c.
+func (g *graph) straight(a, b, c point) bool {
+	if (b.x-a.x)*(c.x-b.x)+(b.y-a.y)*(c.y-b.y) <= 0 {
+		return false
+	}
+	return math.Abs(g.side(a, c, b)) <= g.eps
+}
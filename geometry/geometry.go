@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+// Package geometry implements boolean operations on NanoVGo paths.
+//
+// The operations combine the areas filled by two paths, such as the union of
+// overlapping shapes or a cut-out, which cannot always be expressed with the
+// hole windings of NanoVG alone:
+//
+//     var plate, hole nanovgo.Path
+//     plate.RoundedRect(0, 0, 100, 60, 8)
+//     hole.Circle(100, 30, 20)
+//
+//     var cut = geometry.CombinePaths(&plate, &hole, geometry.Difference, 0.25)
+//     ctx.BeginPath()
+//     ctx.AppendPath(cut)
+//     ctx.Fill()
+//
+// The paths are flattened into polygons, so curves are approximated by line
+// segments. A polygon is the area filled by a list of flattened paths, like
+// the ones returned by nanovgo.Path.Flatten() or
+// nanovgo.Context.FlattenCurrentPath(): every path is closed, and a point is
+// inside if the winding number of the paths around it is nonzero. Because
+// NanoVG orders the points of each path to match its winding, paths with the
+// Solid winding add to the area, and paths with the Hole winding cut it out.
+// Paths may intersect themselves and each other.
+package geometry
+
+import "github.com/beta/nanovgo"
+
+// Op is a boolean operation on polygons.
+type Op int
+
+// Boolean operations.
+const (
+	// Union is the area inside either polygon.
+	Union Op = iota
+	// Intersection is the area inside both polygons.
+	Intersection
+	// Difference is the area inside the first polygon but not the second.
+	Difference
+	// Xor is the area inside exactly one of the polygons.
+	Xor
+)
+
+// String returns the name of the operation.
+func (op Op) String() string {
+	switch op {
+	case Union:
+		return "Union"
+	case Intersection:
+		return "Intersection"
+	case Difference:
+		return "Difference"
+	case Xor:
+		return "Xor"
+	}
+	return "Unknown"
+}
+
+// contains returns whether a point is inside the result of the operation,
+// given whether it is inside the first and the second polygon.
+func (op Op) contains(a, b bool) bool {
+	switch op {
+	case Union:
+		return a || b
+	case Intersection:
+		return a && b
+	case Difference:
+		return a && !b
+	case Xor:
+		return a != b
+	}
+	return false
+}
+
+// Combine returns the polygon resulting from the boolean operation op on the
+// polygons a and b. The returned paths are closed and do not cross each
+// other. Outlines have the Solid winding and holes the Hole winding, so the
+// result can be filled by NanoVG with the paths from ToPath(). Only Points,
+// Closed and Winding of the returned paths are set.
+func Combine(a, b []nanovgo.FlatPath, op Op) []nanovgo.FlatPath {
+	var g = newGraph(a, b)
+	g.split()
+	g.classify(op)
+	return g.contours()
+}
+
+// CombinePaths flattens the paths a and b with tolerance like
+// nanovgo.Path.Flatten(), combines them with Combine(), and returns the
+// result as a path.
+func CombinePaths(a, b *nanovgo.Path, op Op, tolerance float32) *nanovgo.Path {
+	return ToPath(Combine(a.Flatten(tolerance), b.Flatten(tolerance), op))
+}
+
+// ToPath returns a path with a closed sub-path for each of paths, which has
+// the winding of the path.
+func ToPath(paths []nanovgo.FlatPath) *nanovgo.Path {
+	var p nanovgo.Path
+	for _, path := range paths {
+		if len(path.Points) == 0 {
+			continue
+		}
+		p.MoveTo(path.Points[0][0], path.Points[0][1])
+		for _, pt := range path.Points[1:] {
+			p.LineTo(pt[0], pt[1])
+		}
+		p.ClosePath()
+		if path.Winding == nanovgo.CW {
+			p.PathWinding(nanovgo.Hole)
+		} else {
+			p.PathWinding(nanovgo.Solid)
+		}
+	}
+	return &p
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package geometry
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/beta/nanovgo"
+)
+
+func rect(x, y, w, h float32) *nanovgo.Path {
+	var p nanovgo.Path
+	p.Rect(x, y, w, h)
+	return &p
+}
+
+// frame is a 30x30 square with a 10x10 hole in the middle.
+func frame() *nanovgo.Path {
+	var p nanovgo.Path
+	p.Rect(0, 0, 30, 30)
+	p.Rect(10, 10, 10, 10)
+	p.PathWinding(nanovgo.Hole)
+	return &p
+}
+
+// bowtie is a self-intersecting path of two triangles meeting at (5,5).
+func bowtie() *nanovgo.Path {
+	var p nanovgo.Path
+	p.MoveTo(0, 0)
+	p.LineTo(10, 10)
+	p.LineTo(10, 0)
+	p.LineTo(0, 10)
+	p.ClosePath()
+	return &p
+}
+
+// circles returns n circles of radius 10 around a circle of radius 30, whose
+// neighbors overlap, starting with the circle at index first and taking every
+// step-th one.
+func circles(n, first, step int) *nanovgo.Path {
+	var p nanovgo.Path
+	for i := first; i < n; i += step {
+		var a = float64(i) * 2 * math.Pi / float64(n)
+		p.Circle(float32(30*math.Cos(a)), float32(30*math.Sin(a)), 10)
+	}
+	return &p
+}
+
+// area returns the area of a polygon returned by Combine, and false if the
+// order of the points of a path does not match its winding.
+func area(paths []nanovgo.FlatPath) (float64, bool) {
+	var total float64
+	var ok = true
+	for _, path := range paths {
+		var a float64
+		var pts = path.Points
+		for i := range pts {
+			var p0, p1 = pts[i], pts[(i+1)%len(pts)]
+			a += float64(p0[0])*float64(p1[1]) - float64(p1[0])*float64(p0[1])
+		}
+		// NanoVG orders the points of solid paths counterclockwise, which is
+		// clockwise with the y axis pointing down.
+		a /= -2
+		if path.Winding == nanovgo.CW {
+			ok = ok && a < 0
+		} else {
+			ok = ok && a > 0
+		}
+		total += a
+	}
+	return total, ok
+}
+
+func TestCombine(t *testing.T) {
+	var tests = []struct {
+		name string
+		a, b *nanovgo.Path
+		// The area and number of contours of Union, Intersection, Difference
+		// and Xor.
+		area     [4]float64
+		contours [4]int
+	}{
+		{"overlapping squares", rect(0, 0, 10, 10), rect(5, 5, 10, 10), [4]float64{175, 25, 75, 150}, [4]int{1, 1, 1, 2}},
+		{"identical squares", rect(0, 0, 10, 10), rect(0, 0, 10, 10), [4]float64{100, 100, 0, 0}, [4]int{1, 1, 0, 0}},
+		{"shared edge", rect(0, 0, 10, 10), rect(10, 0, 10, 10), [4]float64{200, 0, 100, 200}, [4]int{1, 0, 1, 1}},
+		{"shared partial edge", rect(0, 0, 10, 10), rect(10, 5, 10, 10), [4]float64{200, 0, 100, 200}, [4]int{1, 0, 1, 1}},
+		{"square inside", rect(0, 0, 10, 10), rect(2, 2, 6, 6), [4]float64{100, 36, 64, 64}, [4]int{1, 1, 2, 2}},
+		{"disjoint squares", rect(0, 0, 10, 10), rect(20, 0, 10, 10), [4]float64{200, 0, 100, 200}, [4]int{2, 0, 1, 2}},
+		{"bowtie", bowtie(), rect(0, 0, 10, 5), [4]float64{75, 25, 25, 50}, [4]int{1, 2, 2, 3}},
+		{"bowtie alone", bowtie(), &nanovgo.Path{}, [4]float64{50, 0, 50, 50}, [4]int{2, 0, 2, 2}},
+		{"frame with a hole", frame(), rect(5, 5, 20, 20), [4]float64{900, 300, 500, 600}, [4]int{1, 2, 2, 3}},
+		{"frame and its hole", frame(), rect(10, 10, 10, 10), [4]float64{900, 0, 800, 900}, [4]int{1, 0, 2, 1}},
+	}
+	for _, test := range tests {
+		for op := Union; op <= Xor; op++ {
+			var paths = Combine(test.a.Flatten(0.25), test.b.Flatten(0.25), op)
+			var a, ok = area(paths)
+			if math.Abs(a-test.area[op]) > 1e-3 || len(paths) != test.contours[op] {
+				t.Errorf("%s: %v has area %v with %d contours, want %v with %d", test.name, op, a, len(paths), test.area[op], test.contours[op])
+			}
+			if !ok {
+				t.Errorf("%s: %v has contours whose winding does not match their points", test.name, op)
+			}
+		}
+	}
+}
+
+// TestCombineCircles combines many overlapping curved paths, and compares the
+// area of the result with the area integrated along scanlines.
+func TestCombineCircles(t *testing.T) {
+	var tests = []struct {
+		name     string
+		a, b     *nanovgo.Path
+		op       Op
+		contours int
+	}{
+		// The circles make a ring with a hole in the middle, and so do every
+		// second circle. Their difference is the outer and the inner bump of
+		// each circle.
+		{"one path", circles(30, 0, 1), &nanovgo.Path{}, Union, 2},
+		{"union", circles(30, 0, 2), circles(30, 1, 2), Union, 2},
+		{"intersection", circles(30, 0, 2), circles(30, 1, 2), Intersection, 2},
+		{"difference", circles(30, 0, 2), circles(30, 1, 2), Difference, 30},
+		{"xor", circles(30, 0, 2), circles(30, 1, 2), Xor, 60},
+	}
+	for _, test := range tests {
+		var a, b = test.a.Flatten(0.1), test.b.Flatten(0.1)
+		var paths = Combine(a, b, test.op)
+		var got, ok = area(paths)
+		if !ok {
+			t.Errorf("%s: contours whose winding does not match their points", test.name)
+		}
+		if len(paths) != test.contours {
+			t.Errorf("%s: %d contours, want %d", test.name, len(paths), test.contours)
+		}
+		var want = scanArea(a, b, test.op, -45, 45, 0.05)
+		if math.Abs(got-want) > want*1e-4 {
+			t.Errorf("%s: area %v, want %v", test.name, got, want)
+		}
+	}
+}
+
+type crossing struct {
+	x    float64
+	poly int
+	dir  int
+}
+
+// scanArea returns the area of op applied to the paths a and b between y0 and
+// y1, summing the exact lengths covered on scanlines step apart.
+func scanArea(a, b []nanovgo.FlatPath, op Op, y0, y1, step float64) float64 {
+	var total float64
+	var crossings []crossing
+	for y := y0 + step/2; y < y1; y += step {
+		crossings = crossings[:0]
+		for poly, paths := range [2][]nanovgo.FlatPath{a, b} {
+			for _, path := range paths {
+				var pts = path.Points
+				for i := range pts {
+					var x0, ya = float64(pts[i][0]), float64(pts[i][1])
+					var x1, yb = float64(pts[(i+1)%len(pts)][0]), float64(pts[(i+1)%len(pts)][1])
+					if (ya <= y) == (yb <= y) {
+						continue
+					}
+					var dir = 1
+					if yb < ya {
+						dir = -1
+					}
+					crossings = append(crossings, crossing{x0 + (y-ya)*(x1-x0)/(yb-ya), poly, dir})
+				}
+			}
+		}
+		sort.Slice(crossings, func(i, j int) bool {
+			return crossings[i].x < crossings[j].x
+		})
+		var n [2]int
+		for i, c := range crossings {
+			if i > 0 && op.contains(n[0] != 0, n[1] != 0) {
+				total += (c.x - crossings[i-1].x) * step
+			}
+			n[c.poly] += c.dir
+		}
+	}
+	return total
+}
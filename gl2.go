@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+//go:build gl2
+// +build gl2
+
+package nanovgo
+
+/*
+#cgo CFLAGS: -DNANOVGO_GL2
+#cgo darwin LDFLAGS: -framework OpenGL
+#cgo linux pkg-config: gl
+#define NANOVG_GL2_IMPLEMENTATION
+#include <stdlib.h>
+#include "nanovgo_gl.h"
+#include "nanovg/src/nanovg.h"
+#include "nanovg/src/nanovg_gl.h"
+#include "nanovg/src/nanovg_gl_utils.h"
+*/
+import "C"
+
+// CreateContextGL2 creates a NanoVGo context for OpenGL 2. flags should be a
+// combination of Antialias, StencilStrokes and Debug.
+//
+// The OpenGL 2 backend is only available with the gl2 build tag.
+func CreateContextGL2(flags CreateFlag) *Context {
+	return (*Context)(C.nvgCreateGL2(C.int(flags)))
+}
+
+func deleteContext(ctx *Context) {
+	C.nvgDeleteGL2(ctx.c())
+}
+
+func createImageFromTexture(ctx *Context, tex uint32, width, height int, imageFlags ImageFlag) C.int {
+	return C.nvglCreateImageFromHandleGL2(ctx.c(), C.GLuint(tex), C.int(width), C.int(height), C.int(imageFlags))
+}
+
+func imageTexture(image *Image) uint32 {
+	return uint32(C.nvglImageHandleGL2(image.ctx.c(), image.c()))
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+//go:build gles2 && !gl2
+// +build gles2,!gl2
+
+package nanovgo
+
+/*
+#cgo CFLAGS: -DNANOVGO_GLES2
+#cgo ios LDFLAGS: -framework OpenGLES
+#cgo linux pkg-config: glesv2 egl
+#define NANOVG_GLES2_IMPLEMENTATION
+#include <stdlib.h>
+#include "nanovgo_gl.h"
+#include "nanovg/src/nanovg.h"
+#include "nanovg/src/nanovg_gl.h"
+#include "nanovg/src/nanovg_gl_utils.h"
+*/
+import "C"
+
+// CreateContextGLES2 creates a NanoVGo context for OpenGL ES 2. flags should be
+// a combination of Antialias, StencilStrokes and Debug.
+//
+// The OpenGL ES 2 backend is only available with the gles2 build tag.
+func CreateContextGLES2(flags CreateFlag) *Context {
+	return (*Context)(C.nvgCreateGLES2(C.int(flags)))
+}
+
+func deleteContext(ctx *Context) {
+	C.nvgDeleteGLES2(ctx.c())
+}
+
+func createImageFromTexture(ctx *Context, tex uint32, width, height int, imageFlags ImageFlag) C.int {
+	return C.nvglCreateImageFromHandleGLES2(ctx.c(), C.GLuint(tex), C.int(width), C.int(height), C.int(imageFlags))
+}
+
+func imageTexture(image *Image) uint32 {
+	return uint32(C.nvglImageHandleGLES2(image.ctx.c(), image.c()))
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+/*
+#include "nanovg/src/nanovg.h"
+*/
+import "C"
+import (
+	"image/color"
+	"math"
+	"unsafe"
+)
+
+// MaxGradientStops is the maximum number of color stops of a gradient, further
+// stops are ignored.
+const MaxGradientStops = C.NVG_MAX_GRADIENT_STOPS
+
+// GradientStop is a color stop of a gradient. Offset is the position of the
+// stop in range [0..1] along the gradient.
+type GradientStop struct {
+	Offset float32
+	Color  color.Color
+}
+
+// GradientStops are the color stops of a gradient. The stops do not need to be
+// sorted, stops at the same offset make a hard edge between their colors in
+// the order they are given. The color before the first stop and after the
+// last stop is the color of that stop.
+type GradientStops []GradientStop
+
+// GradientType is the type of a gradient with color stops.
+type GradientType int
+
+// Gradient types.
+const (
+	// GradientNone is the type of box gradients, image patterns and the two
+	// color gradients.
+	GradientNone GradientType = C.NVG_GRADIENT_NONE
+	// GradientLinear is the type of Context.LinearGradientStops().
+	GradientLinear GradientType = C.NVG_GRADIENT_LINEAR
+	// GradientRadial is the type of Context.RadialGradientStops().
+	GradientRadial GradientType = C.NVG_GRADIENT_RADIAL
+	// GradientConic is the type of Context.ConicGradient().
+	GradientConic GradientType = C.NVG_GRADIENT_CONIC
+)
+
+// c returns the stops as NanoVG gradient stops, and a pointer to the first
+// one, which is nil if there are no stops.
+func (stops GradientStops) c() ([]C.NVGgradientStop, *C.NVGgradientStop) {
+	if len(stops) == 0 {
+		return nil, nil
+	}
+	var cStops = make([]C.NVGgradientStop, len(stops))
+	for i, stop := range stops {
+		cStops[i].offset = C.float(stop.Offset)
+		cStops[i].color = toNVGColor(stop.Color)
+	}
+	return cStops, &cStops[0]
+}
+
+// LinearGradientStops creates and returns a linear gradient with color stops.
+// Parameters (startX, startY)-(endX, endY) specify the start and end
+// coordinates of the linear gradient, which are at offset 0 and 1 of stops.
+// At most MaxGradientStops stops are used.
+//
+// The gradient is transformed by the current transform when it is passed to
+// Context.FillPaint() or Context.StrokePaint().
+func (ctx *Context) LinearGradientStops(startX, startY, endX, endY float32, stops GradientStops) Paint {
+	var cStops, p = stops.c()
+	return Paint(C.nvgLinearGradientStops(ctx.c(), C.float(startX), C.float(startY), C.float(endX), C.float(endY), p, C.int(len(cStops))))
+}
+
+// RadialGradientStops creates and returns a radial gradient with color stops.
+// Parameters (centerX, centerY) specify the center, innerRadius and
+// outerRadius specify the inner and outer radius of the gradient, which are at
+// offset 0 and 1 of stops. At most MaxGradientStops stops are used.
+//
+// The gradient is transformed by the current transform when it is passed to
+// Context.FillPaint() or Context.StrokePaint().
+func (ctx *Context) RadialGradientStops(centerX, centerY, innerRadius, outerRadius float32, stops GradientStops) Paint {
+	var cStops, p = stops.c()
+	return Paint(C.nvgRadialGradientStops(ctx.c(), C.float(centerX), C.float(centerY), C.float(innerRadius), C.float(outerRadius), p, C.int(len(cStops))))
+}
+
+// ConicGradient creates and returns a conic gradient, which sweeps clockwise
+// around the center (centerX, centerY) starting at startAngle (in radians).
+// The offsets of stops are in turns from the start angle. At most
+// MaxGradientStops stops are used.
+//
+// The gradient is transformed by the current transform when it is passed to
+// Context.FillPaint() or Context.StrokePaint().
+func (ctx *Context) ConicGradient(centerX, centerY, startAngle float32, stops ...GradientStop) Paint {
+	var cStops, p = GradientStops(stops).c()
+	return Paint(C.nvgConicGradientStops(ctx.c(), C.float(centerX), C.float(centerY), C.float(startAngle), p, C.int(len(cStops))))
+}
+
+// rampSize is the width of the lookup textures of gradients with color stops,
+// see GLNVG_RAMP_SIZE in nanovg_gl.h.
+const rampSize = 256
+
+// gradientRamp returns the lookup texture of the color stops of paint, which
+// holds rampSize premultiplied RGBA texels.
+func gradientRamp(paint *RenderPaint) []byte {
+	var p C.NVGpaint
+	for i, stop := range paint.Stops {
+		if i == MaxGradientStops {
+			break
+		}
+		p.nstops++
+		p.stops[i].offset = C.float(stop.Offset)
+		p.stops[i].color = *(*C.NVGcolor)(unsafe.Pointer(&stop.Color))
+	}
+	var data = make([]byte, rampSize*4)
+	C.nvgGradientRamp(&p, (*C.uchar)(unsafe.Pointer(&data[0])), rampSize)
+	return data
+}
+
+// gradientOffset returns the offset along the gradient with color stops of
+// paint at (x,y) in paint space, clamped to [0,1].
+func gradientOffset(paint *RenderPaint, x, y float32) float32 {
+	var t = x
+	switch paint.Gradient {
+	case GradientRadial:
+		t = float32(math.Sqrt(float64(x*x + y*y)))
+	case GradientConic:
+		var turns = math.Atan2(float64(y), float64(x)) / (2 * math.Pi)
+		t = float32(turns - math.Floor(turns))
+	}
+	return clampf((t-paint.Radius)/(paint.Extent[0]-paint.Radius), 0, 1)
+}
+
+// rampColor returns the premultiplied color of a lookup texture returned by
+// gradientRamp() at offset t, interpolated like a texture sample.
+func rampColor(ramp []byte, t float32) [4]float32 {
+	var x = clampf(t, 0, 1) * (rampSize - 1)
+	var i = int(x)
+	if i > rampSize-2 {
+		i = rampSize - 2
+	}
+	var f = x - float32(i)
+	var color [4]float32
+	for j := range color {
+		color[j] = (float32(ramp[i*4+j])*(1-f) + float32(ramp[(i+1)*4+j])*f) / 255
+	}
+	return color
+}
@@ -0,0 +1,336 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+//go:build linux && !gl2 && !gles2 && !gles3
+// +build linux,!gl2,!gles2,!gles3
+
+package nanovgo
+
+/*
+#include <stdlib.h>
+#include <string.h>
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+#define GL_GLEXT_PROTOTYPES
+#include <GL/gl.h>
+#include <GL/glext.h>
+
+typedef struct nvgoHeadless {
+	EGLDisplay display;
+	EGLSurface surface;
+	EGLContext context;
+	GLuint fbo, color, stencil;
+} nvgoHeadless;
+
+// The EGL display shared by all headless contexts, which is initialized by the
+// first one and terminated when the last one is deleted. eglTerminate() would
+// destroy the contexts of all of them otherwise. The Go side serializes the
+// creation and deletion of contexts.
+static EGLDisplay nvgo__display = EGL_NO_DISPLAY;
+static int nvgo__displayRefs = 0;
+
+static int nvgo__hasExtension(const char* extensions, const char* name)
+{
+	size_t len = strlen(name);
+	const char* s = extensions;
+	if (extensions == NULL) return 0;
+	while ((s = strstr(s, name)) != NULL) {
+		if ((s == extensions || s[-1] == ' ') && (s[len] == ' ' || s[len] == '\0'))
+			return 1;
+		s += len;
+	}
+	return 0;
+}
+
+static EGLDisplay nvgo__eglDisplay(void)
+{
+	PFNEGLGETPLATFORMDISPLAYEXTPROC getPlatformDisplay;
+	if (nvgo__hasExtension(eglQueryString(EGL_NO_DISPLAY, EGL_EXTENSIONS), "EGL_MESA_platform_surfaceless")) {
+		getPlatformDisplay = (PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+		if (getPlatformDisplay != NULL) {
+			EGLDisplay display = getPlatformDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+			if (display != EGL_NO_DISPLAY && eglInitialize(display, NULL, NULL))
+				return display;
+		}
+	}
+	EGLDisplay display = eglGetDisplay(EGL_DEFAULT_DISPLAY);
+	if (display != EGL_NO_DISPLAY && eglInitialize(display, NULL, NULL))
+		return display;
+	return EGL_NO_DISPLAY;
+}
+
+static EGLDisplay nvgo__acquireDisplay(void)
+{
+	if (nvgo__displayRefs == 0) {
+		nvgo__display = nvgo__eglDisplay();
+		if (nvgo__display == EGL_NO_DISPLAY) return EGL_NO_DISPLAY;
+	}
+	nvgo__displayRefs++;
+	return nvgo__display;
+}
+
+static void nvgo__releaseDisplay(void)
+{
+	if (--nvgo__displayRefs == 0) {
+		eglTerminate(nvgo__display);
+		nvgo__display = EGL_NO_DISPLAY;
+	}
+}
+
+// Creates an OpenGL 3.2 core context with EGL, which is made current without a
+// surface if supported, or with a pbuffer surface otherwise.
+static int nvgo__createEGL(nvgoHeadless* h, int width, int height)
+{
+	EGLint configAttribs[] = {
+		EGL_SURFACE_TYPE, EGL_PBUFFER_BIT,
+		EGL_RENDERABLE_TYPE, EGL_OPENGL_BIT,
+		EGL_RED_SIZE, 8, EGL_GREEN_SIZE, 8, EGL_BLUE_SIZE, 8, EGL_ALPHA_SIZE, 8,
+		EGL_STENCIL_SIZE, 8,
+		EGL_NONE
+	};
+	EGLint contextAttribs[] = {
+		EGL_CONTEXT_MAJOR_VERSION, 3,
+		EGL_CONTEXT_MINOR_VERSION, 2,
+		EGL_CONTEXT_OPENGL_PROFILE_MASK, EGL_CONTEXT_OPENGL_CORE_PROFILE_BIT,
+		EGL_NONE
+	};
+	EGLint pbufferAttribs[] = { EGL_WIDTH, width, EGL_HEIGHT, height, EGL_NONE };
+	EGLConfig config;
+	EGLint nconfigs = 0;
+	int surfaceless;
+
+	h->display = nvgo__acquireDisplay();
+	if (h->display == EGL_NO_DISPLAY) return 0;
+	if (!eglBindAPI(EGL_OPENGL_API)) return 0;
+	surfaceless = nvgo__hasExtension(eglQueryString(h->display, EGL_EXTENSIONS), "EGL_KHR_surfaceless_context");
+	if (surfaceless)
+		configAttribs[1] = 0;
+	if (!eglChooseConfig(h->display, configAttribs, &config, 1, &nconfigs) || nconfigs < 1) return 0;
+	h->context = eglCreateContext(h->display, config, EGL_NO_CONTEXT, contextAttribs);
+	if (h->context == EGL_NO_CONTEXT) return 0;
+	if (!surfaceless) {
+		h->surface = eglCreatePbufferSurface(h->display, config, pbufferAttribs);
+		if (h->surface == EGL_NO_SURFACE) return 0;
+	}
+	return eglMakeCurrent(h->display, h->surface, h->surface, h->context);
+}
+
+void nvgoHeadlessMakeCurrent(nvgoHeadless* h)
+{
+	if (eglGetCurrentContext() != h->context)
+		eglMakeCurrent(h->display, h->surface, h->surface, h->context);
+}
+
+void nvgoHeadlessClear(nvgoHeadless* h)
+{
+	nvgoHeadlessMakeCurrent(h);
+	glBindFramebuffer(GL_FRAMEBUFFER, h->fbo);
+	glClearColor(0, 0, 0, 0);
+	glClearStencil(0);
+	glClear(GL_COLOR_BUFFER_BIT | GL_STENCIL_BUFFER_BIT);
+}
+
+// nvgoDeleteHeadless deletes the OpenGL objects, the context and the surface
+// of h. The display is kept until the other headless contexts are deleted.
+void nvgoDeleteHeadless(nvgoHeadless* h)
+{
+	if (h == NULL) return;
+	if (h->context != EGL_NO_CONTEXT && eglMakeCurrent(h->display, h->surface, h->surface, h->context)) {
+		if (h->fbo != 0) {
+			glBindFramebuffer(GL_FRAMEBUFFER, 0);
+			glDeleteFramebuffers(1, &h->fbo);
+		}
+		if (h->color != 0) glDeleteRenderbuffers(1, &h->color);
+		if (h->stencil != 0) glDeleteRenderbuffers(1, &h->stencil);
+		eglMakeCurrent(h->display, EGL_NO_SURFACE, EGL_NO_SURFACE, EGL_NO_CONTEXT);
+	}
+	if (h->display != EGL_NO_DISPLAY) {
+		if (h->surface != EGL_NO_SURFACE) eglDestroySurface(h->display, h->surface);
+		if (h->context != EGL_NO_CONTEXT) eglDestroyContext(h->display, h->context);
+		nvgo__releaseDisplay();
+	}
+	free(h);
+}
+
+// nvgoCreateHeadless creates an offscreen OpenGL context with a framebuffer
+// object of size width*height bound, to which all rendering goes regardless
+// of how the context was created.
+nvgoHeadless* nvgoCreateHeadless(int width, int height)
+{
+	nvgoHeadless* h = (nvgoHeadless*)calloc(1, sizeof(nvgoHeadless));
+	if (h == NULL) return NULL;
+	h->display = EGL_NO_DISPLAY;
+	h->surface = EGL_NO_SURFACE;
+	h->context = EGL_NO_CONTEXT;
+	if (!nvgo__createEGL(h, width, height)) {
+		nvgoDeleteHeadless(h);
+		return NULL;
+	}
+
+	glGenFramebuffers(1, &h->fbo);
+	glBindFramebuffer(GL_FRAMEBUFFER, h->fbo);
+	glGenRenderbuffers(1, &h->color);
+	glBindRenderbuffer(GL_RENDERBUFFER, h->color);
+	glRenderbufferStorage(GL_RENDERBUFFER, GL_RGBA8, width, height);
+	glFramebufferRenderbuffer(GL_FRAMEBUFFER, GL_COLOR_ATTACHMENT0, GL_RENDERBUFFER, h->color);
+	glGenRenderbuffers(1, &h->stencil);
+	glBindRenderbuffer(GL_RENDERBUFFER, h->stencil);
+	glRenderbufferStorage(GL_RENDERBUFFER, GL_DEPTH24_STENCIL8, width, height);
+	glFramebufferRenderbuffer(GL_FRAMEBUFFER, GL_DEPTH_STENCIL_ATTACHMENT, GL_RENDERBUFFER, h->stencil);
+	if (glCheckFramebufferStatus(GL_FRAMEBUFFER) != GL_FRAMEBUFFER_COMPLETE) {
+		nvgoDeleteHeadless(h);
+		return NULL;
+	}
+	glViewport(0, 0, width, height);
+	nvgoHeadlessClear(h);
+	return h;
+}
+
+void nvgoHeadlessReadPixels(nvgoHeadless* h, int width, int height, unsigned char* pixels)
+{
+	nvgoHeadlessMakeCurrent(h);
+	glBindFramebuffer(GL_FRAMEBUFFER, h->fbo);
+	glFinish();
+	glPixelStorei(GL_PACK_ALIGNMENT, 1);
+	glReadPixels(0, 0, width, height, GL_RGBA, GL_UNSIGNED_BYTE, pixels);
+}
+*/
+import "C"
+import (
+	"errors"
+	"image"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// headlessMutex serializes the creation and deletion of headless contexts,
+// which share an EGL display.
+var headlessMutex sync.Mutex
+
+// HeadlessContext is a NanoVGo context rendered by OpenGL 3 offscreen, without
+// a window or display, e.g. for batch jobs and CI.
+//
+// Several headless contexts can be used from the same goroutine. BeginFrame(),
+// EndFrame(), CancelFrame(), Clear(), ReadPixels() and Delete() make the
+// OpenGL context of ctx current. Other calls which use OpenGL outside of a
+// frame, such as CreateImage() and CreateFramebuffer(), use the context made
+// current last, so call MakeCurrent() before them.
+type HeadlessContext struct {
+	*Context
+	width, height int
+	cHeadless     *C.nvgoHeadless
+}
+
+// CreateHeadlessContext creates a NanoVGo context which renders into an
+// offscreen framebuffer of size width*height with the OpenGL 3 backend, so
+// the output is the same as on screen. flags should be a combination of
+// Antialias, StencilStrokes and Debug.
+//
+// The OpenGL context is created with EGL, without a surface if supported or
+// with a pbuffer surface otherwise. Mesa's surfaceless platform is used if
+// available, so that no display is needed.
+//
+// OpenGL contexts belong to a thread, so the calling goroutine is locked to
+// its thread until the context is deleted, and the context must only be used
+// from that goroutine.
+func CreateHeadlessContext(width, height int, flags CreateFlag) (*HeadlessContext, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("nanovgo: invalid headless context size")
+	}
+	runtime.LockOSThread()
+	headlessMutex.Lock()
+	var h = C.nvgoCreateHeadless(C.int(width), C.int(height))
+	headlessMutex.Unlock()
+	if h == nil {
+		runtime.UnlockOSThread()
+		return nil, errors.New("nanovgo: cannot create an OpenGL 3 context with EGL")
+	}
+	var ctx = CreateContext(flags)
+	if ctx == nil {
+		headlessMutex.Lock()
+		C.nvgoDeleteHeadless(h)
+		headlessMutex.Unlock()
+		runtime.UnlockOSThread()
+		return nil, errors.New("nanovgo: cannot create the OpenGL 3 backend")
+	}
+	return &HeadlessContext{
+		Context:   ctx,
+		width:     width,
+		height:    height,
+		cHeadless: h,
+	}, nil
+}
+
+// Size returns the size of the framebuffer of the context.
+func (ctx *HeadlessContext) Size() (width, height int) {
+	return ctx.width, ctx.height
+}
+
+// MakeCurrent makes the OpenGL context of ctx current on the thread.
+func (ctx *HeadlessContext) MakeCurrent() {
+	C.nvgoHeadlessMakeCurrent(ctx.cHeadless)
+}
+
+// BeginFrame makes the OpenGL context current and begins drawing a new frame,
+// see Context.BeginFrame().
+func (ctx *HeadlessContext) BeginFrame(windowWidth, windowHeight, devicePixelRatio float32) {
+	C.nvgoHeadlessMakeCurrent(ctx.cHeadless)
+	ctx.Context.BeginFrame(windowWidth, windowHeight, devicePixelRatio)
+}
+
+// CancelFrame makes the OpenGL context current and cancels drawing the
+// current frame.
+func (ctx *HeadlessContext) CancelFrame() {
+	C.nvgoHeadlessMakeCurrent(ctx.cHeadless)
+	ctx.Context.CancelFrame()
+}
+
+// EndFrame makes the OpenGL context current, and ends drawing and flushes
+// remaining render state.
+func (ctx *HeadlessContext) EndFrame() {
+	C.nvgoHeadlessMakeCurrent(ctx.cHeadless)
+	ctx.Context.EndFrame()
+}
+
+// Clear clears the framebuffer to transparent, which it is when the context
+// is created. Frames are drawn over the previous content otherwise.
+func (ctx *HeadlessContext) Clear() {
+	C.nvgoHeadlessClear(ctx.cHeadless)
+}
+
+// ReadPixels reads the framebuffer of the context. The pixels are
+// premultiplied by alpha, as image.RGBA expects.
+func (ctx *HeadlessContext) ReadPixels() *image.RGBA {
+	var img = image.NewRGBA(image.Rect(0, 0, ctx.width, ctx.height))
+	C.nvgoHeadlessReadPixels(ctx.cHeadless, C.int(ctx.width), C.int(ctx.height), (*C.uchar)(unsafe.Pointer(&img.Pix[0])))
+	flipRows(img)
+	return img
+}
+
+// Delete deletes the NanoVGo context and the OpenGL context, and unlocks the
+// goroutine from its thread. Other headless contexts are not affected.
+func (ctx *HeadlessContext) Delete() {
+	C.nvgoHeadlessMakeCurrent(ctx.cHeadless)
+	ctx.Context.Delete()
+	headlessMutex.Lock()
+	C.nvgoDeleteHeadless(ctx.cHeadless)
+	headlessMutex.Unlock()
+	ctx.cHeadless = nil
+	runtime.UnlockOSThread()
+}
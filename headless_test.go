@@ -0,0 +1,108 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+//go:build linux && !gl2 && !gles2 && !gles3
+// +build linux,!gl2,!gles2,!gles3
+
+package nanovgo
+
+import (
+	"image/color"
+	"testing"
+)
+
+func createHeadless(t *testing.T) *HeadlessContext {
+	t.Helper()
+	var ctx, err = CreateHeadlessContext(20, 20, Antialias)
+	if err != nil {
+		t.Skipf("no EGL device: %v", err)
+	}
+	return ctx
+}
+
+func fillHeadless(ctx *HeadlessContext, c color.Color) {
+	ctx.BeginFrame(20, 20, 1)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 20, 20)
+	ctx.FillColor(c)
+	ctx.Fill()
+	ctx.EndFrame()
+}
+
+func TestHeadlessContext(t *testing.T) {
+	var ctx = createHeadless(t)
+	defer ctx.Delete()
+	if got := ctx.ReadPixels().RGBAAt(10, 10); got != transparent {
+		t.Errorf("new context has pixel %v, want %v", got, transparent)
+	}
+	fillHeadless(ctx, red)
+	if got := ctx.ReadPixels().RGBAAt(10, 10); got != red {
+		t.Errorf("pixel %v, want %v", got, red)
+	}
+	ctx.Clear()
+	if got := ctx.ReadPixels().RGBAAt(10, 10); got != transparent {
+		t.Errorf("cleared context has pixel %v, want %v", got, transparent)
+	}
+}
+
+// TestHeadlessContexts uses two contexts in turn, and keeps using one after
+// the other is deleted.
+func TestHeadlessContexts(t *testing.T) {
+	var a = createHeadless(t)
+	var b = createHeadless(t)
+	defer b.Delete()
+
+	fillHeadless(a, red)
+	fillHeadless(b, blue)
+	if got := a.ReadPixels().RGBAAt(10, 10); got != red {
+		t.Errorf("first context has pixel %v, want %v", got, red)
+	}
+	if got := b.ReadPixels().RGBAAt(10, 10); got != blue {
+		t.Errorf("second context has pixel %v, want %v", got, blue)
+	}
+
+	a.Delete()
+	b.Clear()
+	fillHeadless(b, black)
+	if got := b.ReadPixels().RGBAAt(10, 10); got != black {
+		t.Errorf("second context has pixel %v after deleting the first, want %v", got, black)
+	}
+}
+
+func TestHeadlessBindDefaultFramebuffer(t *testing.T) {
+	var a = createHeadless(t)
+	defer a.Delete()
+	var b = createHeadless(t)
+	defer b.Delete()
+
+	for _, ctx := range []*HeadlessContext{a, b} {
+		ctx.MakeCurrent()
+		var fb = ctx.CreateFramebuffer(20, 20, 0)
+		if fb == nil {
+			t.Fatal("CreateFramebuffer failed")
+		}
+		fb.Bind()
+		fillHeadless(ctx, blue)
+		ctx.BindDefaultFramebuffer()
+		fillHeadless(ctx, red)
+		if got := ctx.ReadPixels().RGBAAt(10, 10); got != red {
+			t.Errorf("pixel %v after binding the default framebuffer, want %v", got, red)
+		}
+		fb.Delete()
+	}
+}
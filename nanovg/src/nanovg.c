@@ -49,14 +49,6 @@
 #define NVG_COUNTOF(arr) (sizeof(arr) / sizeof(0[arr]))
 
 
-enum NVGcommands {
-	NVG_MOVETO = 0,
-	NVG_LINETO = 1,
-	NVG_BEZIERTO = 2,
-	NVG_CLOSE = 3,
-	NVG_WINDING = 4,
-};
-
 enum NVGpointFlags
 {
 	NVG_PT_CORNER = 0x01,
@@ -74,6 +66,9 @@ struct NVGstate {
 	float miterLimit;
 	int lineJoin;
 	int lineCap;
+	float dashes[NVG_MAX_DASHES];
+	int ndashes;
+	float dashOffset;
 	float alpha;
 	float xform[6];
 	NVGscissor scissor;
@@ -696,6 +691,34 @@ void nvgLineJoin(NVGcontext* ctx, int join)
 	state->lineJoin = join;
 }
 
+void nvgStrokeDash(NVGcontext* ctx, const float* pattern, int ndashes, float offset)
+{
+	NVGstate* state = nvg__getState(ctx);
+	float total = 0.0f;
+	int i, n;
+
+	state->ndashes = 0;
+	state->dashOffset = 0.0f;
+	if (ndashes <= 0)
+		return;
+	for (i = 0; i < ndashes; i++) {
+		if (!(pattern[i] >= 0.0f))
+			return;
+	}
+
+	// Repeat odd patterns once so that dashes and gaps alternate.
+	n = (ndashes % 2) ? ndashes*2 : ndashes;
+	n = nvg__mini(n, NVG_MAX_DASHES) & ~1;
+	for (i = 0; i < n; i++) {
+		state->dashes[i] = pattern[i % ndashes];
+		total += state->dashes[i];
+	}
+	if (!(total > 0.0f))
+		return;
+	state->ndashes = n;
+	state->dashOffset = offset;
+}
+
 void nvgGlobalAlpha(NVGcontext* ctx, float alpha)
 {
 	NVGstate* state = nvg__getState(ctx);
@@ -953,6 +976,131 @@ NVGpaint nvgImagePattern(NVGcontext* ctx,
 	return p;
 }
 
+static void nvg__setStops(NVGpaint* p, const NVGgradientStop* stops, int nstops)
+{
+	int i, j;
+	NVGgradientStop stop;
+
+	p->nstops = nvg__clampi(nstops, 0, NVG_MAX_GRADIENT_STOPS);
+	for (i = 0; i < p->nstops; i++) {
+		stop = stops[i];
+		stop.offset = nvg__clampf(stop.offset, 0.0f, 1.0f);
+		// Insertion sort, keeps the order of stops at the same offset.
+		for (j = i; j > 0 && p->stops[j-1].offset > stop.offset; j--)
+			p->stops[j] = p->stops[j-1];
+		p->stops[j] = stop;
+	}
+
+	p->innerColor = p->outerColor = nvgRGBAf(1,1,1,1);
+}
+
+NVGpaint nvgLinearGradientStops(NVGcontext* ctx,
+								float sx, float sy, float ex, float ey,
+								const NVGgradientStop* stops, int nstops)
+{
+	NVGpaint p;
+	float dx, dy, d;
+	NVG_NOTUSED(ctx);
+	memset(&p, 0, sizeof(p));
+
+	// Calculate transform with the x axis along the line
+	dx = ex - sx;
+	dy = ey - sy;
+	d = sqrtf(dx*dx + dy*dy);
+	if (d > 0.0001f) {
+		dx /= d;
+		dy /= d;
+	} else {
+		dx = 1;
+		dy = 0;
+		d = 0.0001f;
+	}
+
+	p.xform[0] = dx; p.xform[1] = dy;
+	p.xform[2] = -dy; p.xform[3] = dx;
+	p.xform[4] = sx; p.xform[5] = sy;
+
+	p.extent[0] = d;
+
+	p.gradient = NVG_GRADIENT_LINEAR;
+	nvg__setStops(&p, stops, nstops);
+
+	return p;
+}
+
+NVGpaint nvgRadialGradientStops(NVGcontext* ctx,
+								float cx, float cy, float inr, float outr,
+								const NVGgradientStop* stops, int nstops)
+{
+	NVGpaint p;
+	NVG_NOTUSED(ctx);
+	memset(&p, 0, sizeof(p));
+
+	nvgTransformIdentity(p.xform);
+	p.xform[4] = cx;
+	p.xform[5] = cy;
+
+	p.radius = nvg__maxf(0.0f, inr);
+	p.extent[0] = nvg__maxf(p.radius + 0.0001f, outr);
+
+	p.gradient = NVG_GRADIENT_RADIAL;
+	nvg__setStops(&p, stops, nstops);
+
+	return p;
+}
+
+NVGpaint nvgConicGradientStops(NVGcontext* ctx,
+							   float cx, float cy, float a,
+							   const NVGgradientStop* stops, int nstops)
+{
+	NVGpaint p;
+	NVG_NOTUSED(ctx);
+	memset(&p, 0, sizeof(p));
+
+	nvgTransformRotate(p.xform, a);
+	p.xform[4] = cx;
+	p.xform[5] = cy;
+
+	p.extent[0] = 1.0f;
+
+	p.gradient = NVG_GRADIENT_CONIC;
+	nvg__setStops(&p, stops, nstops);
+
+	return p;
+}
+
+void nvgGradientRamp(const NVGpaint* paint, unsigned char* dst, int n)
+{
+	int i, j, k;
+	float t, u, a;
+	NVGcolor c0, c1;
+	const NVGgradientStop* stops = paint->stops;
+	int nstops = paint->nstops;
+
+	for (i = 0; i < n; i++) {
+		t = n > 1 ? (float)i / (float)(n-1) : 0.0f;
+		u = 0.0f;
+		if (nstops == 0) {
+			c0 = c1 = nvgRGBAf(0,0,0,0);
+		} else {
+			// Find the last stop at or before t, stops at the same offset form a hard edge.
+			for (k = 0; k+1 < nstops && stops[k+1].offset <= t; k++);
+			c0 = c1 = stops[k].color;
+			if (k+1 < nstops && t > stops[k].offset) {
+				c1 = stops[k+1].color;
+				u = (t - stops[k].offset) / (stops[k+1].offset - stops[k].offset);
+			}
+		}
+		// Interpolate premultiplied colors.
+		for (j = 0; j < 3; j++) {
+			a = c0.rgba[j]*c0.a*(1-u) + c1.rgba[j]*c1.a*u;
+			dst[i*4+j] = (unsigned char)(nvg__clampf(a, 0.0f, 1.0f)*255.0f + 0.5f);
+		}
+		a = c0.a*(1-u) + c1.a*u;
+		dst[i*4+3] = (unsigned char)(nvg__clampf(a, 0.0f, 1.0f)*255.0f + 0.5f);
+	}
+}
+
 // Scissoring
 void nvgScissor(NVGcontext* ctx, float x, float y, float w, float h)
 {
@@ -1417,6 +1565,189 @@ static void nvg__flattenPaths(NVGcontext* ctx)
 	}
 }
 
+struct NVGdashWalk {
+	int first, count, nsegs, closed;
+	int seg;
+	float pos;
+	int idx;
+	float rem;
+	int on;
+};
+typedef struct NVGdashWalk NVGdashWalk;
+
+static void nvg__dashPosition(NVGcontext* ctx, NVGdashWalk* w, float* x, float* y, float* dx, float* dy)
+{
+	NVGpoint* p0 = &ctx->cache->points[w->first + w->seg];
+	*x = p0->x + p0->dx * w->pos;
+	*y = p0->y + p0->dy * w->pos;
+	*dx = p0->dx;
+	*dy = p0->dy;
+}
+
+static void nvg__beginDash(NVGcontext* ctx, NVGdashWalk* w)
+{
+	float x, y, dx, dy;
+	nvg__dashPosition(ctx, w, &x, &y, &dx, &dy);
+	nvg__addPath(ctx);
+	nvg__addPoint(ctx, x, y, NVG_PT_CORNER);
+}
+
+static void nvg__endDash(NVGcontext* ctx, NVGdashWalk* w, int lineCap)
+{
+	NVGpath* path;
+	NVGpoint* pt;
+	float x, y, dx, dy;
+
+	nvg__dashPosition(ctx, w, &x, &y, &dx, &dy);
+	nvg__addPoint(ctx, x, y, NVG_PT_CORNER);
+
+	path = nvg__lastPath(ctx);
+	if (path == NULL || path->count > 1)
+		return;
+	if (lineCap == NVG_BUTT || path->count == 0) {
+		// Dashes of zero length have no area without caps.
+		ctx->cache->npoints -= path->count;
+		ctx->cache->npaths--;
+		return;
+	}
+	// Give dashes of zero length a direction for their caps.
+	pt = nvg__lastPoint(ctx);
+	x = pt->x + dx * ctx->distTol*2;
+	y = pt->y + dy * ctx->distTol*2;
+	nvg__addPoint(ctx, x, y, NVG_PT_CORNER);
+}
+
+// Walks dist along the path, adding the points of dashes to the cache.
+static void nvg__dashWalk(NVGcontext* ctx, NVGdashWalk* w, float dist, const float* dashes, int ndashes, int lineCap)
+{
+	NVGpoint* p0;
+	NVGpoint* p1;
+	float step;
+
+	for (;;) {
+		p0 = &ctx->cache->points[w->first + w->seg];
+		step = nvg__minf(nvg__minf(p0->len - w->pos, w->rem), dist);
+		step = nvg__maxf(step, 0.0f);
+		w->pos += step;
+		w->rem -= step;
+		dist -= step;
+		if (dist <= 0.0f)
+			break;
+		if (w->rem <= 0.0f) {
+			// Toggle between dash and gap.
+			if (w->on)
+				nvg__endDash(ctx, w, lineCap);
+			w->idx = (w->idx+1) % ndashes;
+			w->rem = dashes[w->idx];
+			w->on = !w->on;
+			if (w->on)
+				nvg__beginDash(ctx, w);
+			continue;
+		}
+		// Advance to the next segment.
+		if (!w->closed && w->seg+1 >= w->nsegs)
+			break;
+		p1 = &ctx->cache->points[w->first + (w->seg+1) % w->count];
+		if (w->on)
+			nvg__addPoint(ctx, p1->x, p1->y, p1->flags);
+		w->seg = (w->seg+1) % w->nsegs;
+		w->pos = 0.0f;
+	}
+}
+
+// Splits count flattened paths starting at first into dashes, which are appended to the cache after the paths
+// as open paths. A closed path which is not interrupted by a gap stays closed. The dash at the start of a closed
+// path is joined to the dash at its end, if the end is not in a gap.
+static void nvg__dashPaths(NVGcontext* ctx, int first, int count, const float* dashes, int ndashes, float offset, int lineCap)
+{
+	NVGpathCache* cache = ctx->cache;
+	int npaths = cache->npaths;
+	float total = 0.0f;
+	int i, j;
+
+	for (i = 0; i < ndashes; i++)
+		total += dashes[i];
+	offset = fmodf(offset, total);
+	if (offset < 0.0f)
+		offset += total;
+
+	for (i = first; i < first+count; i++) {
+		NVGpath* path = &cache->paths[i];
+		NVGdashWalk w;
+		float length = 0.0f, skip;
+
+		memset(&w, 0, sizeof(w));
+		w.first = path->first;
+		w.count = path->count;
+		w.closed = path->closed;
+		w.nsegs = path->closed ? path->count : path->count-1;
+		if (w.nsegs < 1)
+			continue;
+		for (j = 0; j < w.nsegs; j++)
+			length += cache->points[w.first + j].len;
+
+		// Find the dash or gap at the start of the path.
+		w.rem = offset;
+		while (w.rem > dashes[w.idx] || (w.rem == dashes[w.idx] && dashes[w.idx] > 0.0f)) {
+			w.rem -= dashes[w.idx];
+			w.idx = (w.idx+1) % ndashes;
+		}
+		w.rem = dashes[w.idx] - w.rem;
+		w.on = (w.idx % 2) == 0;
+
+		if (path->closed && w.on) {
+			skip = w.rem;
+			if (skip >= length) {
+				// The whole path is a single dash.
+				nvg__addPath(ctx);
+				for (j = 0; j < w.count; j++) {
+					NVGpoint* pt = &cache->points[w.first + j];
+					nvg__addPoint(ctx, pt->x, pt->y, pt->flags);
+				}
+				nvg__lastPath(ctx)->closed = 1;
+				continue;
+			}
+			// Skip the first dash, it is added after the last one.
+			w.on = 0;
+			nvg__dashWalk(ctx, &w, skip, dashes, ndashes, lineCap);
+			w.idx = (w.idx+1) % ndashes;
+			w.rem = dashes[w.idx];
+			nvg__dashWalk(ctx, &w, length - skip, dashes, ndashes, lineCap);
+			if (!w.on) {
+				// The path ends in a gap, start the first dash on its own.
+				w.seg = 0;
+				w.pos = 0.0f;
+				w.on = 1;
+				nvg__beginDash(ctx, &w);
+			}
+			w.rem = skip;
+			w.idx = 0;
+			nvg__dashWalk(ctx, &w, skip, dashes, ndashes, lineCap);
+			nvg__endDash(ctx, &w, lineCap);
+		} else {
+			if (w.on)
+				nvg__beginDash(ctx, &w);
+			nvg__dashWalk(ctx, &w, length, dashes, ndashes, lineCap);
+			if (w.on)
+				nvg__endDash(ctx, &w, lineCap);
+		}
+	}
+
+	// Calculate the direction and length of the segments of the dashes.
+	for (i = npaths; i < cache->npaths; i++) {
+		NVGpath* path = &cache->paths[i];
+		NVGpoint* pts = &cache->points[path->first];
+		NVGpoint* p0 = &pts[path->count-1];
+		NVGpoint* p1 = &pts[0];
+		for (j = 0; j < path->count; j++) {
+			p0->dx = p1->x - p0->x;
+			p0->dy = p1->y - p0->y;
+			p0->len = nvg__normalize(&p0->dx, &p0->dy);
+			p0 = p1++;
+		}
+	}
+}
+
 static int nvg__curveDivs(float r, float arc, float tol)
 {
 	float da = acosf(r / (r + tol)) * 2.0f;
@@ -2054,6 +2385,58 @@ void nvgArcTo(NVGcontext* ctx, float x1, float y1, float x2, float y2, float rad
 	nvgArc(ctx, cx, cy, radius, a0, a1, dir);
 }
 
+void nvgAppendPath(NVGcontext* ctx, const float* commands, int ncommands)
+{
+	NVGstate* state = nvg__getState(ctx);
+	float* vals;
+	int i;
+
+	if (ncommands <= 0)
+		return;
+
+	if (ctx->ncommands+ncommands > ctx->ccommands) {
+		float* cmds;
+		int ccommands = ctx->ncommands+ncommands + ctx->ccommands/2;
+		cmds = (float*)realloc(ctx->commands, sizeof(float)*ccommands);
+		if (cmds == NULL) return;
+		ctx->commands = cmds;
+		ctx->ccommands = ccommands;
+	}
+
+	vals = &ctx->commands[ctx->ncommands];
+	memcpy(vals, commands, ncommands*sizeof(float));
+
+	// Transform commands, the last point is kept untransformed like nvg__appendCommands() does.
+	i = 0;
+	while (i < ncommands) {
+		int cmd = (int)vals[i];
+		switch (cmd) {
+		case NVG_MOVETO:
+		case NVG_LINETO:
+			ctx->commandx = vals[i+1];
+			ctx->commandy = vals[i+2];
+			nvgTransformPoint(&vals[i+1],&vals[i+2], state->xform, vals[i+1],vals[i+2]);
+			i += 3;
+			break;
+		case NVG_BEZIERTO:
+			ctx->commandx = vals[i+5];
+			ctx->commandy = vals[i+6];
+			nvgTransformPoint(&vals[i+1],&vals[i+2], state->xform, vals[i+1],vals[i+2]);
+			nvgTransformPoint(&vals[i+3],&vals[i+4], state->xform, vals[i+3],vals[i+4]);
+			nvgTransformPoint(&vals[i+5],&vals[i+6], state->xform, vals[i+5],vals[i+6]);
+			i += 7;
+			break;
+		case NVG_WINDING:
+			i += 2;
+			break;
+		default:
+			i++;
+		}
+	}
+
+	ctx->ncommands += ncommands;
+}
+
 void nvgClosePath(NVGcontext* ctx)
 {
 	float vals[] = { NVG_CLOSE };
@@ -2251,8 +2634,11 @@ void nvgStroke(NVGcontext* ctx)
 	float scale = nvg__getAverageScale(state->xform);
 	float strokeWidth = nvg__clampf(state->strokeWidth * scale, 0.0f, 200.0f);
 	NVGpaint strokePaint = state->stroke;
+	NVGpathCache* cache = ctx->cache;
 	const NVGpath* path;
-	int i;
+	NVGpath* paths = NULL;
+	float dashes[NVG_MAX_DASHES];
+	int i, npaths = 0, npoints = 0;
 
 
 	if (strokeWidth < ctx->fringeWidth) {
@@ -2270,6 +2656,18 @@ void nvgStroke(NVGcontext* ctx)
 
 	nvg__flattenPaths(ctx);
 
+	if (state->ndashes > 0) {
+		// Stroke the dashes instead of the paths, the paths are kept for filling.
+		npaths = cache->npaths;
+		npoints = cache->npoints;
+		for (i = 0; i < state->ndashes; i++)
+			dashes[i] = state->dashes[i] * scale;
+		nvg__dashPaths(ctx, 0, npaths, dashes, state->ndashes, state->dashOffset * scale, state->lineCap);
+		paths = cache->paths;
+		cache->paths += npaths;
+		cache->npaths -= npaths;
+	}
+
 	if (ctx->params.edgeAntiAlias && state->shapeAntiAlias)
 		nvg__expandStroke(ctx, strokeWidth*0.5f, ctx->fringeWidth, state->lineCap, state->lineJoin, state->miterLimit);
 	else
@@ -2284,6 +2682,12 @@ void nvgStroke(NVGcontext* ctx)
 		ctx->strokeTriCount += path->nstroke-2;
 		ctx->drawCallCount++;
 	}
+
+	if (state->ndashes > 0) {
+		cache->paths = paths;
+		cache->npaths = npaths;
+		cache->npoints = npoints;
+	}
 }
 
 // Add fonts
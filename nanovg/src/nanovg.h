@@ -42,6 +42,21 @@ struct NVGcolor {
 };
 typedef struct NVGcolor NVGcolor;
 
+#define NVG_MAX_GRADIENT_STOPS 8
+
+struct NVGgradientStop {
+	float offset;
+	NVGcolor color;
+};
+typedef struct NVGgradientStop NVGgradientStop;
+
+enum NVGgradientType {
+	NVG_GRADIENT_NONE = 0,	// Box gradients, image patterns and the two color gradients.
+	NVG_GRADIENT_LINEAR,	// Linear gradient with color stops.
+	NVG_GRADIENT_RADIAL,	// Radial gradient with color stops.
+	NVG_GRADIENT_CONIC,		// Conic gradient with color stops.
+};
+
 struct NVGpaint {
 	float xform[6];
 	float extent[2];
@@ -50,6 +65,9 @@ struct NVGpaint {
 	NVGcolor innerColor;
 	NVGcolor outerColor;
 	int image;
+	int gradient;			// NVGgradientType of gradients with color stops.
+	int nstops;
+	NVGgradientStop stops[NVG_MAX_GRADIENT_STOPS];
 };
 typedef struct NVGpaint NVGpaint;
 
@@ -63,6 +81,14 @@ enum NVGsolidity {
 	NVG_HOLE = 2,			// CW
 };
 
+enum NVGcommands {
+	NVG_MOVETO = 0,			// x, y
+	NVG_LINETO = 1,			// x, y
+	NVG_BEZIERTO = 2,		// c1x, c1y, c2x, c2y, x, y
+	NVG_CLOSE = 3,
+	NVG_WINDING = 4,		// dir
+};
+
 enum NVGlineCap {
 	NVG_BUTT,
 	NVG_ROUND,
@@ -268,6 +294,14 @@ void nvgLineCap(NVGcontext* ctx, int cap);
 // Can be one of NVG_MITER (default), NVG_ROUND, NVG_BEVEL.
 void nvgLineJoin(NVGcontext* ctx, int join);
 
+#define NVG_MAX_DASHES 32
+
+// Sets the dash pattern of the stroke style. The ndashes lengths of pattern alternate between dashes and gaps,
+// a pattern with an odd number of lengths is repeated to make it even. Offset specifies how far into the pattern
+// the stroke starts. An empty pattern, a pattern of zero total length or a pattern with negative lengths sets
+// solid strokes. At most NVG_MAX_DASHES lengths are used.
+void nvgStrokeDash(NVGcontext* ctx, const float* pattern, int ndashes, float offset);
+
 // Sets the transparency applied to all rendered shapes.
 // Already transparent paths will get proportionally more transparent as well.
 void nvgGlobalAlpha(NVGcontext* ctx, float alpha);
@@ -392,6 +426,7 @@ void nvgDeleteImage(NVGcontext* ctx, int image);
 // Paints
 //
 // NanoVG supports four types of paints: linear gradient, box gradient, radial gradient and image pattern.
+// Gradients with any number of color stops can be linear, radial or conic.
 // These can be used as paints for strokes and fills.
 
 // Creates and returns a linear gradient. Parameters (sx,sy)-(ex,ey) specify the start and end coordinates
@@ -420,6 +455,31 @@ NVGpaint nvgRadialGradient(NVGcontext* ctx, float cx, float cy, float inr, float
 NVGpaint nvgImagePattern(NVGcontext* ctx, float ox, float oy, float ex, float ey,
 						 float angle, int image, float alpha);
 
+// Creates and returns a linear gradient with color stops. Parameters (sx,sy)-(ex,ey) specify the start and end
+// coordinates of the linear gradient, stops the nstops colors at offsets in range [0..1] along the gradient.
+// The stops are sorted by offset, and at most NVG_MAX_GRADIENT_STOPS stops are used.
+// The gradient is transformed by the current transform when it is passed to nvgFillPaint() or nvgStrokePaint().
+NVGpaint nvgLinearGradientStops(NVGcontext* ctx, float sx, float sy, float ex, float ey,
+								const NVGgradientStop* stops, int nstops);
+
+// Creates and returns a radial gradient with color stops. Parameters (cx,cy) specify the center, inr and outr
+// specify the inner and outer radius of the gradient, which are at offset 0 and 1 of the stops.
+// The stops are sorted by offset, and at most NVG_MAX_GRADIENT_STOPS stops are used.
+// The gradient is transformed by the current transform when it is passed to nvgFillPaint() or nvgStrokePaint().
+NVGpaint nvgRadialGradientStops(NVGcontext* ctx, float cx, float cy, float inr, float outr,
+								const NVGgradientStop* stops, int nstops);
+
+// Creates and returns a conic gradient with color stops, which sweeps clockwise around the center (cx,cy)
+// starting at angle a (in radians), stops are the nstops colors at offsets in range [0..1] of a full turn.
+// The stops are sorted by offset, and at most NVG_MAX_GRADIENT_STOPS stops are used.
+// The gradient is transformed by the current transform when it is passed to nvgFillPaint() or nvgStrokePaint().
+NVGpaint nvgConicGradientStops(NVGcontext* ctx, float cx, float cy, float a,
+							   const NVGgradientStop* stops, int nstops);
+
+// Fills dst with n premultiplied RGBA texels of the colors of the gradient stops of paint, texel i
+// being the color at offset i/(n-1). Renderers use it as a lookup texture of the gradient.
+void nvgGradientRamp(const NVGpaint* paint, unsigned char* dst, int n);
+
 //
 // Scissoring
 //
@@ -502,6 +562,11 @@ void nvgEllipse(NVGcontext* ctx, float cx, float cy, float rx, float ry);
 // Creates new circle shaped sub-path.
 void nvgCircle(NVGcontext* ctx, float cx, float cy, float r);
 
+// Appends path commands to the current path. Commands are ncommands values, each command (NVGcommands)
+// followed by its arguments. The points are transformed by the current transform like the points of the
+// other path functions.
+void nvgAppendPath(NVGcontext* ctx, const float* commands, int ncommands);
+
 // Fills the current path with current fill style.
 void nvgFill(NVGcontext* ctx);
 
@@ -124,9 +124,16 @@ enum GLNVGshaderType {
 	NSVG_SHADER_FILLGRAD,
 	NSVG_SHADER_FILLIMG,
 	NSVG_SHADER_SIMPLE,
-	NSVG_SHADER_IMG
+	NSVG_SHADER_IMG,
+	NSVG_SHADER_FILLLINEAR,
+	NSVG_SHADER_FILLRADIAL,
+	NSVG_SHADER_FILLCONIC
 };
 
+// Width of the lookup textures of gradients with color stops, which is also
+// used by the fragment shader.
+#define GLNVG_RAMP_SIZE 256
+
 #if NANOVG_GL_USE_UNIFORMBUFFER
 enum GLNVGuniformBindings {
 	GLNVG_FRAG_BINDING = 0,
@@ -150,6 +157,14 @@ struct GLNVGtexture {
 };
 typedef struct GLNVGtexture GLNVGtexture;
 
+struct GLNVGramp {
+	NVGgradientStop stops[NVG_MAX_GRADIENT_STOPS];
+	int nstops;
+	int image;
+	int used;
+};
+typedef struct GLNVGramp GLNVGramp;
+
 struct GLNVGblend
 {
 	GLenum srcRGB;
@@ -235,6 +250,9 @@ struct GLNVGcontext {
 	int ntextures;
 	int ctextures;
 	int textureId;
+	GLNVGramp* ramps;
+	int nramps;
+	int cramps;
 	GLuint vertBuf;
 #if defined NANOVG_GL3
 	GLuint vertArr;
@@ -244,6 +262,7 @@ struct GLNVGcontext {
 #endif
 	int fragSize;
 	int flags;
+	GLint defaultFBO;		// Framebuffer bound when the context was created.
 
 	// Per frame buffers
 	GLNVGcall* calls;
@@ -665,6 +684,25 @@ static int glnvg__renderCreate(void* uptr)
 		"		if (texType == 2) color = vec4(color.x);"
 		"		color *= scissor;\n"
 		"		result = color * innerCol;\n"
+		"	} else {					// Gradient with color stops\n"
+		"		// Calculate the offset along the gradient, and look up its color.\n"
+		"		vec2 pt = (paintMat * vec3(fpos,1.0)).xy;\n"
+		"		float t;\n"
+		"		if (type == 4) t = pt.x;\n"
+		"		else if (type == 5) t = length(pt);\n"
+		"		else t = fract(atan(pt.y, pt.x) / 6.28318530718);\n"
+		"		t = clamp((t - radius) / feather, 0.0, 1.0);\n"
+		"		vec2 tc = vec2((t*255.0 + 0.5) / 256.0, 0.5);\n"
+		"#ifdef NANOVG_GL3\n"
+		"		vec4 color = texture(tex, tc);\n"
+		"#else\n"
+		"		vec4 color = texture2D(tex, tc);\n"
+		"#endif\n"
+		"		// Apply alpha.\n"
+		"		color *= innerCol;\n"
+		"		// Combine alpha\n"
+		"		color *= strokeAlpha * scissor;\n"
+		"		result = color;\n"
 		"	}\n"
 		"#ifdef NANOVG_GL3\n"
 		"	outColor = result;\n"
@@ -700,6 +738,11 @@ static int glnvg__renderCreate(void* uptr)
 #endif
 	gl->fragSize = sizeof(GLNVGfragUniforms) + align - sizeof(GLNVGfragUniforms) % align;
 
+	// Remember the framebuffer to return to, see nvgluBindDefaultFramebuffer().
+#if !defined(NANOVG_GL2) || defined(__APPLE__)
+	glGetIntegerv(GL_FRAMEBUFFER_BINDING, &gl->defaultFBO);
+#endif
+
 	glnvg__checkError(gl, "create done");
 
 	glFinish();
@@ -898,6 +941,66 @@ static NVGcolor glnvg__premulColor(NVGcolor c)
 	return c;
 }
 
+// Returns the lookup texture of the color stops of a gradient, which is
+// created on first use and deleted after a frame it is not used in.
+static int glnvg__rampImage(GLNVGcontext* gl, NVGpaint* paint)
+{
+	GLNVGramp* ramp = NULL;
+	unsigned char data[GLNVG_RAMP_SIZE*4];
+	int i;
+
+	for (i = 0; i < gl->nramps; i++) {
+		if (gl->ramps[i].nstops == paint->nstops &&
+			memcmp(gl->ramps[i].stops, paint->stops, sizeof(NVGgradientStop)*paint->nstops) == 0) {
+			gl->ramps[i].used = 1;
+			return gl->ramps[i].image;
+		}
+	}
+
+	if (gl->nramps+1 > gl->cramps) {
+		GLNVGramp* ramps;
+		int cramps = glnvg__maxi(gl->nramps+1, 4) + gl->cramps/2; // 1.5x Overallocate
+		ramps = (GLNVGramp*)realloc(gl->ramps, sizeof(GLNVGramp)*cramps);
+		if (ramps == NULL) return 0;
+		gl->ramps = ramps;
+		gl->cramps = cramps;
+	}
+
+	nvgGradientRamp(paint, data, GLNVG_RAMP_SIZE);
+	ramp = &gl->ramps[gl->nramps];
+	memset(ramp, 0, sizeof(*ramp));
+	memcpy(ramp->stops, paint->stops, sizeof(NVGgradientStop)*paint->nstops);
+	ramp->nstops = paint->nstops;
+	ramp->image = glnvg__renderCreateTexture(gl, NVG_TEXTURE_RGBA, GLNVG_RAMP_SIZE, 1, NVG_IMAGE_PREMULTIPLIED, data);
+	if (ramp->image == 0) return 0;
+	ramp->used = 1;
+	gl->nramps++;
+	return ramp->image;
+}
+
+// Deletes the lookup textures not used since the last call.
+static void glnvg__releaseRamps(GLNVGcontext* gl)
+{
+	int i, n = 0;
+	for (i = 0; i < gl->nramps; i++) {
+		if (gl->ramps[i].used) {
+			gl->ramps[i].used = 0;
+			gl->ramps[n++] = gl->ramps[i];
+		} else {
+			glnvg__deleteTexture(gl, gl->ramps[i].image);
+		}
+	}
+	gl->nramps = n;
+}
+
+// Returns the image a paint is drawn with.
+static int glnvg__paintImage(GLNVGcontext* gl, NVGpaint* paint)
+{
+	if (paint->gradient != NVG_GRADIENT_NONE)
+		return glnvg__rampImage(gl, paint);
+	return paint->image;
+}
+
 static int glnvg__convertPaint(GLNVGcontext* gl, GLNVGfragUniforms* frag, NVGpaint* paint,
 							   NVGscissor* scissor, float width, float fringe, float strokeThr)
 {
@@ -928,7 +1031,20 @@ static int glnvg__convertPaint(GLNVGcontext* gl, GLNVGfragUniforms* frag, NVGpai
 	frag->strokeMult = (width*0.5f + fringe*0.5f) / fringe;
 	frag->strokeThr = strokeThr;
 
-	if (paint->image != 0) {
+	if (paint->gradient != NVG_GRADIENT_NONE) {
+		// The gradient runs from radius to extent[0] along the x axis of
+		// linear gradients, away from the center of radial gradients, or in
+		// turns around the center of conic gradients.
+		if (paint->gradient == NVG_GRADIENT_LINEAR)
+			frag->type = NSVG_SHADER_FILLLINEAR;
+		else if (paint->gradient == NVG_GRADIENT_RADIAL)
+			frag->type = NSVG_SHADER_FILLRADIAL;
+		else
+			frag->type = NSVG_SHADER_FILLCONIC;
+		frag->radius = paint->radius;
+		frag->feather = paint->extent[0] - paint->radius;
+		nvgTransformInverse(invxform, paint->xform);
+	} else if (paint->image != 0) {
 		tex = glnvg__findTexture(gl, paint->image);
 		if (tex == NULL) return 0;
 		if ((tex->flags & NVG_IMAGE_FLIPY) != 0) {
@@ -1253,6 +1369,8 @@ static void glnvg__renderFlush(void* uptr)
 	gl->npaths = 0;
 	gl->ncalls = 0;
 	gl->nuniforms = 0;
+
+	glnvg__releaseRamps(gl);
 }
 
 static int glnvg__maxVertCount(const NVGpath* paths, int npaths)
@@ -1358,7 +1476,7 @@ static void glnvg__renderFill(void* uptr, NVGpaint* paint, NVGcompositeOperation
 	call->pathOffset = glnvg__allocPaths(gl, npaths);
 	if (call->pathOffset == -1) goto error;
 	call->pathCount = npaths;
-	call->image = paint->image;
+	call->image = glnvg__paintImage(gl, paint);
 	call->blendFunc = glnvg__blendCompositeOperation(compositeOperation);
 
 	if (npaths == 1 && paths[0].convex)
@@ -1437,7 +1555,7 @@ static void glnvg__renderStroke(void* uptr, NVGpaint* paint, NVGcompositeOperati
 	call->pathOffset = glnvg__allocPaths(gl, npaths);
 	if (call->pathOffset == -1) goto error;
 	call->pathCount = npaths;
-	call->image = paint->image;
+	call->image = glnvg__paintImage(gl, paint);
 	call->blendFunc = glnvg__blendCompositeOperation(compositeOperation);
 
 	// Allocate vertices for all the paths.
@@ -1539,6 +1657,7 @@ static void glnvg__renderDelete(void* uptr)
 			glDeleteTextures(1, &gl->textures[i].tex);
 	}
 	free(gl->textures);
+	free(gl->ramps);
 
 	free(gl->paths);
 	free(gl->verts);
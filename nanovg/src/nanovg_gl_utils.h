@@ -31,6 +31,8 @@ typedef struct NVGLUframebuffer NVGLUframebuffer;
 void nvgluBindFramebuffer(NVGLUframebuffer* fb);
 NVGLUframebuffer* nvgluCreateFramebuffer(NVGcontext* ctx, int w, int h, int imageFlags);
 void nvgluDeleteFramebuffer(NVGLUframebuffer* fb);
+// Binds the framebuffer which was bound when ctx was created.
+void nvgluBindDefaultFramebuffer(NVGcontext* ctx);
 
 #endif // NANOVG_GL_UTILS_H
 
@@ -130,6 +132,16 @@ void nvgluBindFramebuffer(NVGLUframebuffer* fb)
 #endif
 }
 
+void nvgluBindDefaultFramebuffer(NVGcontext* ctx)
+{
+#ifdef NANOVG_FBO_VALID
+	GLNVGcontext* gl = (GLNVGcontext*)nvgInternalParams(ctx)->userPtr;
+	glBindFramebuffer(GL_FRAMEBUFFER, (GLuint)gl->defaultFBO);
+#else
+	NVG_NOTUSED(ctx);
+#endif
+}
+
 void nvgluDeleteFramebuffer(NVGLUframebuffer* fb)
 {
 #ifdef NANOVG_FBO_VALID
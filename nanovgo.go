@@ -22,13 +22,14 @@
 package nanovgo
 
 /*
-#cgo darwin LDFLAGS: -framework OpenGL
-#define NANOVG_GL3_IMPLEMENTATION
 #include <stdlib.h>
-#include <OpenGL/gl3.h>
 #include "nanovg/src/nanovg.h"
 #include "nanovg/src/nanovg_gl.h"
-#include "nanovg/src/nanovg_gl_utils.h"
+
+float nvgoText(NVGcontext* ctx, float x, float y, const char* string, const char* end);
+void nvgoTextBox(NVGcontext* ctx, float x, float y, float breakRowWidth, const char* string, const char* end);
+int nvgoIsInternal(NVGcontext* ctx);
+float nvgoGlobalAlpha(NVGcontext* ctx);
 */
 import "C"
 import (
@@ -52,20 +53,17 @@ const (
 	Debug CreateFlag = C.NVG_DEBUG
 )
 
-// CreateContext creates a NanoVGo context for OpenGL 3. flags should be a
-// combination of Antialias, StencilStrokes and Debug.
-func CreateContext(flags CreateFlag) *Context {
-	return (*Context)(C.nvgCreateGL3(C.int(flags)))
-}
-
-// Delete deletes a NanoVGo context.
+// Delete deletes a NanoVGo context with the destructor of the backend it was
+// created with.
 func (ctx *Context) Delete() {
-	C.nvgDeleteGL3((*C.NVGcontext)(ctx))
+	if !deleteInternalContext(ctx) {
+		deleteContext(ctx)
+	}
 }
 
+// toNVGColor converts c to a non-premultiplied float color, see ColorModel.
 func toNVGColor(c color.Color) C.NVGcolor {
-	r, g, b, a := c.RGBA()
-	return C.nvgRGBA(C.uchar(r), C.uchar(g), C.uchar(b), C.uchar(a))
+	return colorOf(c).c()
 }
 
 // Paint is a paint style used for painting.
@@ -240,6 +238,9 @@ const (
 	ImageFlipy           ImageFlag = C.NVG_IMAGE_FLIPY
 	ImagePremultiplied   ImageFlag = C.NVG_IMAGE_PREMULTIPLIED
 	ImageNearest         ImageFlag = C.NVG_IMAGE_NEAREST
+	// ImageNoDelete indicates that the OpenGL texture of an image created
+	// with Context.CreateImageFromTexture() is not deleted with the image.
+	ImageNoDelete ImageFlag = C.NVG_IMAGE_NODELETE
 )
 
 // Context is a NanoVGo context for vector graphics rendering.
@@ -389,6 +390,30 @@ func (ctx *Context) LineJoin(join LineJoin) {
 	C.nvgLineJoin(ctx.c(), C.int(join))
 }
 
+// MaxDashes is the maximum number of lengths in a dash pattern.
+const MaxDashes = C.NVG_MAX_DASHES
+
+// StrokeDash sets the dash pattern of the stroke style, like setLineDash() and
+// lineDashOffset of the HTML canvas. The lengths of pattern alternate between
+// dashes and gaps, and a pattern with an odd number of lengths is repeated to
+// make it even. offset specifies how far into the pattern the stroke starts.
+//
+// Dashes follow curves and corners of the path, and are drawn with the current
+// line cap and line join. Dashes of zero length are drawn as dots with round
+// and square caps. A nil or empty pattern, a pattern of zero total length or a
+// pattern with negative lengths sets solid strokes. Patterns longer than
+// MaxDashes are truncated.
+//
+// Like other stroke styles, the dash pattern is saved by Save() and restored
+// by Restore().
+func (ctx *Context) StrokeDash(pattern []float32, offset float32) {
+	var p *C.float
+	if len(pattern) > 0 {
+		p = (*C.float)(unsafe.Pointer(&pattern[0]))
+	}
+	C.nvgStrokeDash(ctx.c(), p, C.int(len(pattern)), C.float(offset))
+}
+
 // GlobalAlpha sets the transparency applied to all rendered shapes.
 //
 // Already transparent paths will get proportionally more transparent as well.
@@ -396,6 +421,11 @@ func (ctx *Context) GlobalAlpha(alpha float32) {
 	C.nvgGlobalAlpha(ctx.c(), C.float(alpha))
 }
 
+// CurrentGlobalAlpha returns the transparency set by Context.GlobalAlpha().
+func (ctx *Context) CurrentGlobalAlpha() float32 {
+	return float32(C.nvgoGlobalAlpha(ctx.c()))
+}
+
 // Transforms.
 //
 // The paths, gradients, patterns and scissor regions are transformed by an
@@ -702,6 +732,29 @@ func (ctx *Context) CreateImageRGBA(width, height int, imageFlags ImageFlag, dat
 	}
 }
 
+// CreateImageFromTexture creates an image from the OpenGL texture tex of size
+// width*height, to draw textures rendered by other OpenGL code. The texture is
+// deleted with the image unless imageFlags has ImageNoDelete set. Returns nil
+// if ctx is not rendered by OpenGL.
+func (ctx *Context) CreateImageFromTexture(tex uint32, width, height int, imageFlags ImageFlag) *Image {
+	if C.nvgoIsInternal(ctx.c()) != 0 {
+		return nil
+	}
+	return &Image{
+		cImage: createImageFromTexture(ctx, tex, width, height, imageFlags),
+		ctx:    ctx,
+	}
+}
+
+// Texture returns the OpenGL texture of the image, or 0 if the context of the
+// image is not rendered by OpenGL.
+func (image *Image) Texture() uint32 {
+	if C.nvgoIsInternal(image.ctx.c()) != 0 {
+		return 0
+	}
+	return imageTexture(image)
+}
+
 // UpdateImage updates image data.
 func (image *Image) UpdateImage(data []uint8) {
 	var cData = make([]C.uchar, len(data))
@@ -777,6 +830,20 @@ func (ctx *Context) ImagePattern(x, y, imageWidth, imageHeight, angle float32, i
 	return Paint(C.nvgImagePattern(ctx.c(), C.float(x), C.float(y), C.float(imageWidth), C.float(imageHeight), C.float(angle), image.cImage, C.float(alpha)))
 }
 
+// ImagePatternTint creates and returns an image pattern like
+// Context.ImagePattern(), whose pixels are multiplied by tint. White images,
+// such as monochrome icons, are drawn in the color of tint.
+//
+// The image pattern is transformed by the current transform when it is passed
+// to Context.FillPaint() or Context.StrokePaint(), so the image is scaled and
+// rotated with the shapes it fills.
+func (ctx *Context) ImagePatternTint(x, y, imageWidth, imageHeight, angle float32, image *Image, tint Color) Paint {
+	var paint = C.nvgImagePattern(ctx.c(), C.float(x), C.float(y), C.float(imageWidth), C.float(imageHeight), C.float(angle), image.cImage, 1)
+	paint.innerColor = tint.c()
+	paint.outerColor = paint.innerColor
+	return Paint(paint)
+}
+
 // Scissoring.
 //
 // Scissoring allows you to clip the rendering into a rectangle. This is useful
@@ -1055,7 +1122,7 @@ func (ctx *Context) Text(x, y float32, text string) {
 	var cText = C.CString(text)
 	defer C.free(unsafe.Pointer(cText))
 
-	C.nvgText(ctx.c(), C.float(x), C.float(y), cText, (*C.char)(C.NULL))
+	C.nvgoText(ctx.c(), C.float(x), C.float(y), cText, (*C.char)(C.NULL))
 }
 
 // TextBox draws multi-line text at location (x,y) wrapped at the width
@@ -1070,7 +1137,7 @@ func (ctx *Context) TextBox(x, y, breakRowWidth float32, text string) {
 	var cText = C.CString(text)
 	defer C.free(unsafe.Pointer(cText))
 
-	C.nvgTextBox(ctx.c(), C.float(x), C.float(y), C.float(breakRowWidth), cText, (*C.char)(C.NULL))
+	C.nvgoTextBox(ctx.c(), C.float(x), C.float(y), C.float(breakRowWidth), cText, (*C.char)(C.NULL))
 }
 
 // TextBounds measures the specified text. Returns the horizontal advance of the
@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+// The OpenGL headers of the backend selected by the build tags. The file of
+// the backend defines NANOVGO_GL2, NANOVGO_GL3, NANOVGO_GLES2 or NANOVGO_GLES3
+// for the whole package.
+
+#ifndef NANOVGO_GL_H
+#define NANOVGO_GL_H
+
+#if defined(NANOVGO_GL2)
+#	if defined(__APPLE__)
+#		include <OpenGL/gl.h>
+#		include <OpenGL/glext.h>
+#	else
+#		define GL_GLEXT_PROTOTYPES
+#		include <GL/gl.h>
+#		include <GL/glext.h>
+#	endif
+#elif defined(NANOVGO_GL3)
+#	if defined(__APPLE__)
+#		include <OpenGL/gl3.h>
+#	else
+#		define GL_GLEXT_PROTOTYPES
+#		include <GL/gl.h>
+#		include <GL/glext.h>
+#	endif
+#elif defined(NANOVGO_GLES2)
+#	if defined(__APPLE__)
+#		include <OpenGLES/ES2/gl.h>
+#		include <OpenGLES/ES2/glext.h>
+#	else
+#		include <GLES2/gl2.h>
+#		include <GLES2/gl2ext.h>
+#	endif
+#elif defined(NANOVGO_GLES3)
+#	if defined(__APPLE__)
+#		include <OpenGLES/ES3/gl.h>
+#		include <OpenGLES/ES3/glext.h>
+#	else
+#		include <GLES3/gl3.h>
+#	endif
+#endif
+
+#endif // NANOVGO_GL_H
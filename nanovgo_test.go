@@ -0,0 +1,82 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"image"
+	"testing"
+)
+
+func TestStrokeDash(t *testing.T) {
+	var tests = []struct {
+		name    string
+		pattern []float32
+		offset  float32
+		cap     LineCap
+		// The start and end x of each dash, or nil for a solid stroke.
+		want [][2]float32
+	}{
+		{"even", []float32{10, 5}, 0, Butt, [][2]float32{{0, 10}, {15, 25}, {30, 40}, {45, 55}, {60, 70}, {75, 85}, {90, 100}}},
+		{"single length", []float32{10}, 0, Butt, [][2]float32{{0, 10}, {20, 30}, {40, 50}, {60, 70}, {80, 90}}},
+		{"odd", []float32{5, 5, 10}, 0, Butt, [][2]float32{{0, 5}, {10, 20}, {25, 30}, {40, 45}, {50, 60}, {65, 70}, {80, 85}, {90, 100}}},
+		{"offset", []float32{10, 5}, 5, Butt, [][2]float32{{0, 5}, {10, 20}, {25, 35}, {40, 50}, {55, 65}, {70, 80}, {85, 95}}},
+		{"negative offset", []float32{10, 5}, -5, Butt, [][2]float32{{5, 15}, {20, 30}, {35, 45}, {50, 60}, {65, 75}, {80, 90}, {95, 100}}},
+		{"dots", []float32{0, 10}, 0, RoundCap, [][2]float32{{0, 0}, {10, 10}, {20, 20}, {30, 30}, {40, 40}, {50, 50}, {60, 60}, {70, 70}, {80, 80}, {90, 90}}},
+		{"butt dots", []float32{0, 10}, 0, Butt, [][2]float32{}},
+		{"negative length", []float32{10, -5}, 0, Butt, nil},
+		{"zero total", []float32{0, 0}, 0, Butt, nil},
+		{"nil", nil, 0, Butt, nil},
+	}
+	var ctx = CreateSoftwareContext(image.NewRGBA(image.Rect(0, 0, 100, 100)), 0)
+	defer ctx.Delete()
+	var near = func(a, b float32) bool {
+		return a-b < 0.05 && b-a < 0.05
+	}
+	for _, test := range tests {
+		ctx.BeginFrame(100, 100, 1)
+		ctx.StrokeDash(test.pattern, test.offset)
+		ctx.LineCap(test.cap)
+		ctx.BeginPath()
+		ctx.MoveTo(0, 50)
+		ctx.LineTo(100, 50)
+		var paths = ctx.TessellateCurrentPath(TessellateStroke)
+		ctx.EndFrame()
+		if len(paths) != 1 {
+			t.Errorf("%s: %d paths, want 1", test.name, len(paths))
+			continue
+		}
+		var path = paths[0]
+		if test.want == nil {
+			if len(path.Dashes) != 0 || len(path.Stroke) == 0 {
+				t.Errorf("%s: %d dashes and %d stroke vertices, want a solid stroke", test.name, len(path.Dashes), len(path.Stroke))
+			}
+			continue
+		}
+		if len(path.Stroke) != 0 || len(path.Dashes) != len(test.want) {
+			t.Errorf("%s: %d dashes and %d stroke vertices, want %d dashes", test.name, len(path.Dashes), len(path.Stroke), len(test.want))
+			continue
+		}
+		for i, dash := range path.Dashes {
+			var x0, x1 = dash.Points[0][0], dash.Points[len(dash.Points)-1][0]
+			if !near(x0, test.want[i][0]) || !near(x1, test.want[i][1]) || len(dash.Stroke) == 0 {
+				t.Errorf("%s: dash %d from %v to %v with %d vertices, want from %v to %v", test.name, i, x0, x1, len(dash.Stroke), test.want[i][0], test.want[i][1])
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+/*
+#include "nanovg/src/nanovg.h"
+*/
+import "C"
+import "image/color"
+
+// SolidPaint returns a paint of a solid color c, which is the paint set by
+// Context.FillColor() and Context.StrokeColor().
+func SolidPaint(c color.Color) Paint {
+	var paint C.NVGpaint
+	C.nvgTransformIdentity(&paint.xform[0])
+	paint.feather = 1
+	paint.innerColor = toNVGColor(c)
+	paint.outerColor = paint.innerColor
+	return Paint(paint)
+}
+
+// Transform returns the transform of the paint, which maps the space the paint
+// is defined in to the space it is drawn in. The current transform is applied
+// on top of it when the paint is passed to Context.FillPaint() or
+// Context.StrokePaint().
+func (paint Paint) Transform() [6]float32 {
+	var xform [6]float32
+	for i := range xform {
+		xform[i] = float32(paint.xform[i])
+	}
+	return xform
+}
+
+// WithTransform returns the paint with its transform replaced by xform, see
+// Paint.Transform(). Paints can be created in a local space, and moved with
+// the transform functions, e.g.
+//
+//     var xform [6]float32
+//     nanovgo.TransformTranslate(&xform, x, y)
+//     nanovgo.TransformPremultiply(&xform, paint.Transform())
+//     ctx.FillPaint(paint.WithTransform(xform))
+func (paint Paint) WithTransform(xform [6]float32) Paint {
+	for i := range xform {
+		paint.xform[i] = C.float(xform[i])
+	}
+	return paint
+}
+
+// Extent returns the extent of the paint. It is the half size of the
+// rectangle of gradients, and the size of the image of image patterns.
+func (paint Paint) Extent() [2]float32 {
+	return [2]float32{float32(paint.extent[0]), float32(paint.extent[1])}
+}
+
+// Radius returns the corner radius of the rectangle of gradients, or the inner
+// radius of radial gradients with color stops.
+func (paint Paint) Radius() float32 {
+	return float32(paint.radius)
+}
+
+// Feather returns the width of the transition from the inner color to the
+// outer color of gradients.
+func (paint Paint) Feather() float32 {
+	return float32(paint.feather)
+}
+
+// InnerColor returns the inner color of gradients, or the color of solid
+// paints. It is the tint of image patterns and gradients with color stops.
+func (paint Paint) InnerColor() Color {
+	return goNVGColor(paint.innerColor)
+}
+
+// OuterColor returns the outer color of gradients.
+func (paint Paint) OuterColor() Color {
+	return goNVGColor(paint.outerColor)
+}
+
+// Image returns the image of image patterns created in ctx, or nil if the
+// paint has no image.
+func (paint Paint) Image(ctx *Context) *Image {
+	if paint.image == 0 {
+		return nil
+	}
+	return &Image{
+		cImage: paint.image,
+		ctx:    ctx,
+	}
+}
+
+// Gradient returns the type of gradients with color stops, or GradientNone for
+// other paints.
+func (paint Paint) Gradient() GradientType {
+	return GradientType(paint.gradient)
+}
+
+// Stops returns the color stops of gradients with color stops, sorted by
+// offset.
+func (paint Paint) Stops() GradientStops {
+	if paint.nstops == 0 {
+		return nil
+	}
+	var stops = make(GradientStops, paint.nstops)
+	for i := range stops {
+		stops[i] = GradientStop{float32(paint.stops[i].offset), goNVGColor(paint.stops[i].color)}
+	}
+	return stops
+}
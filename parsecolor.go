@@ -0,0 +1,365 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseColor parses a CSS color string. It accepts hex colors in the forms
+// #rgb, #rgba, #rrggbb and #rrggbbaa, the functional notations rgb(), rgba(),
+// hsl() and hsla() with either comma or space separated arguments, and the
+// CSS named colors including "transparent". Parsing is case-insensitive.
+func ParseColor(s string) (Color, error) {
+	var str = strings.ToLower(strings.TrimSpace(s))
+	if strings.HasPrefix(str, "#") {
+		if c, ok := parseHexColor(str[1:]); ok {
+			return c, nil
+		}
+		return Color{}, fmt.Errorf("nanovgo: invalid hex color %q", s)
+	}
+	if i := strings.IndexByte(str, '('); i >= 0 {
+		if !strings.HasSuffix(str, ")") {
+			return Color{}, fmt.Errorf("nanovgo: missing ')' in color %q", s)
+		}
+		var name = strings.TrimSpace(str[:i])
+		var args, ok = splitColorArgs(str[i+1 : len(str)-1])
+		if !ok {
+			return Color{}, fmt.Errorf("nanovgo: invalid arguments in color %q", s)
+		}
+		var c Color
+		switch name {
+		case "rgb", "rgba":
+			c, ok = parseRGBArgs(args)
+		case "hsl", "hsla":
+			c, ok = parseHSLArgs(args)
+		default:
+			return Color{}, fmt.Errorf("nanovgo: unknown color function %q", name)
+		}
+		if !ok {
+			return Color{}, fmt.Errorf("nanovgo: invalid arguments in color %q", s)
+		}
+		return c, nil
+	}
+	if str == "transparent" {
+		return Color{}, nil
+	}
+	if rgb, ok := namedColors[str]; ok {
+		return RGB(uint8(rgb>>16), uint8(rgb>>8), uint8(rgb)), nil
+	}
+	return Color{}, fmt.Errorf("nanovgo: unknown color %q", s)
+}
+
+func parseHexColor(s string) (Color, bool) {
+	var v, err = strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, false
+	}
+	var n = uint32(v)
+	switch len(s) {
+	case 3:
+		n = n<<4 | 0xf
+		fallthrough
+	case 4:
+		// Expand each digit to a byte, e.g. 0xabcd to 0xaabbccdd.
+		n = (n&0xf000)<<12 | (n&0xf00)<<8 | (n&0xf0)<<4 | n&0xf
+		n |= n << 4
+	case 6:
+		n = n<<8 | 0xff
+	case 8:
+	default:
+		return Color{}, false
+	}
+	return RGBA(uint8(n>>24), uint8(n>>16), uint8(n>>8), uint8(n)), true
+}
+
+// splitColorArgs splits the arguments of a color function. Both the legacy
+// syntax "r, g, b, a" and the modern syntax "r g b / a" are accepted, and the
+// alpha value, if any, is always the fourth argument.
+func splitColorArgs(s string) ([]string, bool) {
+	var args []string
+	if strings.Contains(s, ",") {
+		args = strings.Split(s, ",")
+		for i := range args {
+			args[i] = strings.TrimSpace(args[i])
+		}
+	} else {
+		var alpha string
+		var hasAlpha bool
+		if i := strings.IndexByte(s, '/'); i >= 0 {
+			s, alpha, hasAlpha = s[:i], strings.TrimSpace(s[i+1:]), true
+		}
+		args = strings.Fields(s)
+		if hasAlpha {
+			if len(args) != 3 {
+				return nil, false
+			}
+			args = append(args, alpha)
+		}
+	}
+	if len(args) != 3 && len(args) != 4 {
+		return nil, false
+	}
+	return args, true
+}
+
+// parseColorNumber parses a number or a percentage. Percentages are scaled so
+// that 100% is equal to max.
+func parseColorNumber(s string, max float32) (float32, bool) {
+	var percent = strings.HasSuffix(s, "%")
+	if percent {
+		s = s[:len(s)-1]
+	}
+	var v, err = strconv.ParseFloat(s, 32)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	if percent {
+		return float32(v) / 100 * max, true
+	}
+	return float32(v), true
+}
+
+// parseAlpha parses the optional fourth argument of a color function as a
+// number in range [0..1] or a percentage.
+func parseAlpha(args []string) (float32, bool) {
+	if len(args) < 4 {
+		return 1, true
+	}
+	var a, ok = parseColorNumber(args[3], 1)
+	return clampf(a, 0, 1), ok
+}
+
+func parseRGBArgs(args []string) (Color, bool) {
+	var rgb [3]float32
+	for i := range rgb {
+		var v, ok = parseColorNumber(args[i], 255)
+		if !ok {
+			return Color{}, false
+		}
+		rgb[i] = clampf(v, 0, 255) / 255
+	}
+	var a, ok = parseAlpha(args)
+	if !ok {
+		return Color{}, false
+	}
+	return RGBAf(rgb[0], rgb[1], rgb[2], a), true
+}
+
+func parseHSLArgs(args []string) (Color, bool) {
+	var h, ok = parseHue(args[0])
+	if !ok {
+		return Color{}, false
+	}
+	// Saturation and lightness are percentages, plain numbers are accepted
+	// as in CSS Color Level 4.
+	var s, l float32
+	if s, ok = parseColorNumber(strings.TrimSuffix(args[1], "%"), 1); !ok {
+		return Color{}, false
+	}
+	if l, ok = parseColorNumber(strings.TrimSuffix(args[2], "%"), 1); !ok {
+		return Color{}, false
+	}
+	var a float32
+	if a, ok = parseAlpha(args); !ok {
+		return Color{}, false
+	}
+	return HSL(h, clampf(s/100, 0, 1), clampf(l/100, 0, 1)).WithAlphaf(a), true
+}
+
+// parseHue parses a CSS hue angle and returns it in turns, which is the hue
+// range used by HSL.
+func parseHue(s string) (float32, bool) {
+	var units = []struct {
+		suffix string
+		turn   float64
+	}{
+		{"deg", 360},
+		{"grad", 400},
+		{"rad", 2 * math.Pi},
+		{"turn", 1},
+	}
+	var turn = 360.0
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, turn = s[:len(s)-len(u.suffix)], u.turn
+			break
+		}
+	}
+	var v, err = strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return float32(v / turn), true
+}
+
+// namedColors maps the CSS named colors to their 0xRRGGBB values.
+var namedColors = map[string]uint32{
+	"aliceblue":            0xf0f8ff,
+	"antiquewhite":         0xfaebd7,
+	"aqua":                 0x00ffff,
+	"aquamarine":           0x7fffd4,
+	"azure":                0xf0ffff,
+	"beige":                0xf5f5dc,
+	"bisque":               0xffe4c4,
+	"black":                0x000000,
+	"blanchedalmond":       0xffebcd,
+	"blue":                 0x0000ff,
+	"blueviolet":           0x8a2be2,
+	"brown":                0xa52a2a,
+	"burlywood":            0xdeb887,
+	"cadetblue":            0x5f9ea0,
+	"chartreuse":           0x7fff00,
+	"chocolate":            0xd2691e,
+	"coral":                0xff7f50,
+	"cornflowerblue":       0x6495ed,
+	"cornsilk":             0xfff8dc,
+	"crimson":              0xdc143c,
+	"cyan":                 0x00ffff,
+	"darkblue":             0x00008b,
+	"darkcyan":             0x008b8b,
+	"darkgoldenrod":        0xb8860b,
+	"darkgray":             0xa9a9a9,
+	"darkgreen":            0x006400,
+	"darkgrey":             0xa9a9a9,
+	"darkkhaki":            0xbdb76b,
+	"darkmagenta":          0x8b008b,
+	"darkolivegreen":       0x556b2f,
+	"darkorange":           0xff8c00,
+	"darkorchid":           0x9932cc,
+	"darkred":              0x8b0000,
+	"darksalmon":           0xe9967a,
+	"darkseagreen":         0x8fbc8f,
+	"darkslateblue":        0x483d8b,
+	"darkslategray":        0x2f4f4f,
+	"darkslategrey":        0x2f4f4f,
+	"darkturquoise":        0x00ced1,
+	"darkviolet":           0x9400d3,
+	"deeppink":             0xff1493,
+	"deepskyblue":          0x00bfff,
+	"dimgray":              0x696969,
+	"dimgrey":              0x696969,
+	"dodgerblue":           0x1e90ff,
+	"firebrick":            0xb22222,
+	"floralwhite":          0xfffaf0,
+	"forestgreen":          0x228b22,
+	"fuchsia":              0xff00ff,
+	"gainsboro":            0xdcdcdc,
+	"ghostwhite":           0xf8f8ff,
+	"gold":                 0xffd700,
+	"goldenrod":            0xdaa520,
+	"gray":                 0x808080,
+	"green":                0x008000,
+	"greenyellow":          0xadff2f,
+	"grey":                 0x808080,
+	"honeydew":             0xf0fff0,
+	"hotpink":              0xff69b4,
+	"indianred":            0xcd5c5c,
+	"indigo":               0x4b0082,
+	"ivory":                0xfffff0,
+	"khaki":                0xf0e68c,
+	"lavender":             0xe6e6fa,
+	"lavenderblush":        0xfff0f5,
+	"lawngreen":            0x7cfc00,
+	"lemonchiffon":         0xfffacd,
+	"lightblue":            0xadd8e6,
+	"lightcoral":           0xf08080,
+	"lightcyan":            0xe0ffff,
+	"lightgoldenrodyellow": 0xfafad2,
+	"lightgray":            0xd3d3d3,
+	"lightgreen":           0x90ee90,
+	"lightgrey":            0xd3d3d3,
+	"lightpink":            0xffb6c1,
+	"lightsalmon":          0xffa07a,
+	"lightseagreen":        0x20b2aa,
+	"lightskyblue":         0x87cefa,
+	"lightslategray":       0x778899,
+	"lightslategrey":       0x778899,
+	"lightsteelblue":       0xb0c4de,
+	"lightyellow":          0xffffe0,
+	"lime":                 0x00ff00,
+	"limegreen":            0x32cd32,
+	"linen":                0xfaf0e6,
+	"magenta":              0xff00ff,
+	"maroon":               0x800000,
+	"mediumaquamarine":     0x66cdaa,
+	"mediumblue":           0x0000cd,
+	"mediumorchid":         0xba55d3,
+	"mediumpurple":         0x9370db,
+	"mediumseagreen":       0x3cb371,
+	"mediumslateblue":      0x7b68ee,
+	"mediumspringgreen":    0x00fa9a,
+	"mediumturquoise":      0x48d1cc,
+	"mediumvioletred":      0xc71585,
+	"midnightblue":         0x191970,
+	"mintcream":            0xf5fffa,
+	"mistyrose":            0xffe4e1,
+	"moccasin":             0xffe4b5,
+	"navajowhite":          0xffdead,
+	"navy":                 0x000080,
+	"oldlace":              0xfdf5e6,
+	"olive":                0x808000,
+	"olivedrab":            0x6b8e23,
+	"orange":               0xffa500,
+	"orangered":            0xff4500,
+	"orchid":               0xda70d6,
+	"palegoldenrod":        0xeee8aa,
+	"palegreen":            0x98fb98,
+	"paleturquoise":        0xafeeee,
+	"palevioletred":        0xdb7093,
+	"papayawhip":           0xffefd5,
+	"peachpuff":            0xffdab9,
+	"peru":                 0xcd853f,
+	"pink":                 0xffc0cb,
+	"plum":                 0xdda0dd,
+	"powderblue":           0xb0e0e6,
+	"purple":               0x800080,
+	"rebeccapurple":        0x663399,
+	"red":                  0xff0000,
+	"rosybrown":            0xbc8f8f,
+	"royalblue":            0x4169e1,
+	"saddlebrown":          0x8b4513,
+	"salmon":               0xfa8072,
+	"sandybrown":           0xf4a460,
+	"seagreen":             0x2e8b57,
+	"seashell":             0xfff5ee,
+	"sienna":               0xa0522d,
+	"silver":               0xc0c0c0,
+	"skyblue":              0x87ceeb,
+	"slateblue":            0x6a5acd,
+	"slategray":            0x708090,
+	"slategrey":            0x708090,
+	"snow":                 0xfffafa,
+	"springgreen":          0x00ff7f,
+	"steelblue":            0x4682b4,
+	"tan":                  0xd2b48c,
+	"teal":                 0x008080,
+	"thistle":              0xd8bfd8,
+	"tomato":               0xff6347,
+	"turquoise":            0x40e0d0,
+	"violet":               0xee82ee,
+	"wheat":                0xf5deb3,
+	"white":                0xffffff,
+	"whitesmoke":           0xf5f5f5,
+	"yellow":               0xffff00,
+	"yellowgreen":          0x9acd32,
+}
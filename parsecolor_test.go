@@ -0,0 +1,79 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import "testing"
+
+func TestParseColor(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want Color
+	}{
+		{"#f80", RGB(0xff, 0x88, 0x00)},
+		{"#f808", RGBA(0xff, 0x88, 0x00, 0x88)},
+		{"#FF8000", RGB(0xff, 0x80, 0x00)},
+		{"#ff800080", RGBA(0xff, 0x80, 0x00, 0x80)},
+		{"  #123456 ", RGB(0x12, 0x34, 0x56)},
+		{"rgb(255, 0, 0)", RGB(255, 0, 0)},
+		{"rgba(0, 0, 255, 0.25)", RGBAf(0, 0, 1, 0.25)},
+		{"rgb(255 0 0 / 50%)", RGBAf(1, 0, 0, 0.5)},
+		{"rgb(100% 50% 0%)", RGBAf(1, 0.5, 0, 1)},
+		{"rgb(300, -10, 0)", RGB(255, 0, 0)},
+		{"hsl(120deg 100% 50%)", RGB(0, 255, 0)},
+		{"hsl(120, 100%, 25%)", RGBAf(0, 0.5, 0, 1)},
+		{"hsla(0.5turn, 100%, 50%, 0.5)", RGBAf(0, 1, 1, 0.5)},
+		{"HSL(240 100% 50%)", RGB(0, 0, 255)},
+		{"red", RGB(255, 0, 0)},
+		{"CornflowerBlue", RGB(0x64, 0x95, 0xed)},
+		{"transparent", Color{}},
+	}
+	for _, test := range tests {
+		var got, err = ParseColor(test.s)
+		if err != nil {
+			t.Errorf("ParseColor(%q) returned error: %v", test.s, err)
+			continue
+		}
+		if !colorNear(got, test.want) {
+			t.Errorf("ParseColor(%q) = %v, want %v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestParseColorErrors(t *testing.T) {
+	var tests = []string{
+		"",
+		"#",
+		"#12345",
+		"#1234567",
+		"#ggg",
+		"rgb(1,2)",
+		"rgb(1, 2, 3, 4, 5)",
+		"rgb(1 2 / 0.5)",
+		"rgb(1, 2, x)",
+		"rgb(1, 2, 3",
+		"cmyk(0, 0, 0, 0)",
+		"hsl(red, 100%, 50%)",
+		"notacolor",
+	}
+	for _, s := range tests {
+		if c, err := ParseColor(s); err == nil {
+			t.Errorf("ParseColor(%q) = %v, want error", s, c)
+		}
+	}
+}
@@ -0,0 +1,556 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+/*
+#include "nanovg/src/nanovg.h"
+*/
+import "C"
+import (
+	"math"
+	"unsafe"
+)
+
+// Path commands, which are stored in Path followed by their arguments.
+const (
+	pathMoveTo   = C.NVG_MOVETO
+	pathLineTo   = C.NVG_LINETO
+	pathBezierTo = C.NVG_BEZIERTO
+	pathClose    = C.NVG_CLOSE
+	pathWinding  = C.NVG_WINDING
+)
+
+// kappa90 is the length of the control points of a bezier quarter circle of
+// radius 1.
+const kappa90 = 0.5522847493
+
+// pathDistTol is the distance under which points are considered equal, as the
+// distance tolerance of a Context with a device pixel ratio of 1.
+const pathDistTol = 0.01
+
+// Path is a retained path. It is built once with the same functions as the
+// path functions of Context, and appended to the current path of a Context
+// with Context.AppendPath() in one call, which is faster than issuing the
+// commands every frame:
+//
+//     var icon nanovgo.Path
+//     icon.RoundedRect(0, 0, 32, 32, 4)
+//     icon.Circle(16, 16, 8)
+//     icon.PathWinding(nanovgo.Hole)
+//
+//     ctx.BeginPath()
+//     ctx.AppendPath(&icon)
+//     ctx.Fill()
+//
+// The points of a path are not transformed until it is appended, where the
+// current transform of the Context is applied. The zero value is an empty
+// path ready to use.
+type Path struct {
+	commands []float32
+	// The last point, which QuadTo() and ArcTo() start from.
+	lastX, lastY float32
+}
+
+// Reset clears the path, keeping its allocated memory.
+func (p *Path) Reset() {
+	p.commands = p.commands[:0]
+	p.lastX, p.lastY = 0, 0
+}
+
+// Empty returns true if the path has no commands.
+func (p *Path) Empty() bool {
+	return len(p.commands) == 0
+}
+
+// Clone returns a copy of the path.
+func (p *Path) Clone() *Path {
+	var clone = *p
+	clone.commands = append([]float32(nil), p.commands...)
+	return &clone
+}
+
+func (p *Path) append(vals ...float32) {
+	if cmd := int(vals[0]); cmd != pathClose && cmd != pathWinding {
+		p.lastX, p.lastY = vals[len(vals)-2], vals[len(vals)-1]
+	}
+	p.commands = append(p.commands, vals...)
+}
+
+// MoveTo starts a new sub-path with point (x,y) as the first point.
+func (p *Path) MoveTo(x, y float32) {
+	p.append(pathMoveTo, x, y)
+}
+
+// LineTo adds a line segment from the last point in the path to point (x,y).
+func (p *Path) LineTo(x, y float32) {
+	p.append(pathLineTo, x, y)
+}
+
+// BezierTo adds a cubic bezier segment from the last point in the path via two
+// control points ((c1X,c1Y) and (c2X,c2Y)) to point (x,y).
+func (p *Path) BezierTo(c1X, c1Y, c2X, c2Y, x, y float32) {
+	p.append(pathBezierTo, c1X, c1Y, c2X, c2Y, x, y)
+}
+
+// QuadTo adds a quadratic bezier segment from the last point in the path via a
+// control point (cX,cY) to point (x,y).
+func (p *Path) QuadTo(cX, cY, x, y float32) {
+	var x0, y0 = p.lastX, p.lastY
+	p.append(pathBezierTo,
+		x0+2.0/3.0*(cX-x0), y0+2.0/3.0*(cY-y0),
+		x+2.0/3.0*(cX-x), y+2.0/3.0*(cY-y),
+		x, y)
+}
+
+// ArcTo adds an arc segment at the corner defined by the last path point, and
+// two points (x1,y1) and (x2,y2).
+func (p *Path) ArcTo(x1, y1, x2, y2, radius float32) {
+	var x0, y0 = p.lastX, p.lastY
+	if len(p.commands) == 0 {
+		return
+	}
+
+	// Handle degenerate cases.
+	if ptEquals(x0, y0, x1, y1, pathDistTol) ||
+		ptEquals(x1, y1, x2, y2, pathDistTol) ||
+		distPtSeg(x1, y1, x0, y0, x2, y2) < pathDistTol*pathDistTol ||
+		radius < pathDistTol {
+		p.LineTo(x1, y1)
+		return
+	}
+
+	// Calculate tangential circle to lines (x0,y0)-(x1,y1) and (x1,y1)-(x2,y2).
+	var dx0, dy0 = normalize(x0-x1, y0-y1)
+	var dx1, dy1 = normalize(x2-x1, y2-y1)
+	var a = math.Acos(float64(dx0*dx1 + dy0*dy1))
+	var d = radius / float32(math.Tan(a/2))
+
+	if d > 10000 {
+		p.LineTo(x1, y1)
+		return
+	}
+
+	var cx, cy, a0, a1 float32
+	var dir Winding
+	if dx1*dy0-dx0*dy1 > 0 {
+		cx = x1 + dx0*d + dy0*radius
+		cy = y1 + dy0*d + -dx0*radius
+		a0 = atan2f(dx0, -dy0)
+		a1 = atan2f(-dx1, dy1)
+		dir = CW
+	} else {
+		cx = x1 + dx0*d + -dy0*radius
+		cy = y1 + dy0*d + dx0*radius
+		a0 = atan2f(-dx0, dy0)
+		a1 = atan2f(dx1, -dy1)
+		dir = CCW
+	}
+
+	p.Arc(cx, cy, radius, a0, a1, dir)
+}
+
+// ClosePath closes current sub-path with a line segment.
+func (p *Path) ClosePath() {
+	p.append(pathClose)
+}
+
+// PathWinding sets the current sub-path winding, see Winding.
+func (p *Path) PathWinding(direction Winding) {
+	p.append(pathWinding, float32(direction))
+}
+
+// Arc creates a new circle arc shaped sub-path. The arc center is at (x,y), the
+// arc radius is radius, and the arc is drawn from angle angle0 to angle1, and
+// swept in direction direction (CCW or CW). If the path is not empty, the arc
+// is connected to the last point with a line segment.
+//
+// Angles are specified in radians.
+func (p *Path) Arc(x, y, radius, angle0, angle1 float32, direction Winding) {
+	var move float32 = pathMoveTo
+	if len(p.commands) > 0 {
+		move = pathLineTo
+	}
+
+	// Clamp angles
+	var da = angle1 - angle0
+	if direction == CW {
+		if absf(da) >= math.Pi*2 {
+			da = math.Pi * 2
+		} else {
+			for da < 0 {
+				da += math.Pi * 2
+			}
+		}
+	} else {
+		if absf(da) >= math.Pi*2 {
+			da = -math.Pi * 2
+		} else {
+			for da > 0 {
+				da -= math.Pi * 2
+			}
+		}
+	}
+
+	// Split arc into max 90 degree segments.
+	var ndivs = int(absf(da)/(math.Pi*0.5) + 0.5)
+	if ndivs < 1 {
+		ndivs = 1
+	} else if ndivs > 5 {
+		ndivs = 5
+	}
+	var hda = float64(da/float32(ndivs)) / 2
+	var kappa = absf(float32(4.0 / 3.0 * (1 - math.Cos(hda)) / math.Sin(hda)))
+	if direction == CCW {
+		kappa = -kappa
+	}
+
+	var px, py, ptanX, ptanY float32
+	for i := 0; i <= ndivs; i++ {
+		var a = float64(angle0 + da*(float32(i)/float32(ndivs)))
+		var dx, dy = float32(math.Cos(a)), float32(math.Sin(a))
+		var x1, y1 = x + dx*radius, y + dy*radius
+		var tanX, tanY = -dy * radius * kappa, dx * radius * kappa
+		if i == 0 {
+			p.append(move, x1, y1)
+		} else {
+			p.append(pathBezierTo, px+ptanX, py+ptanY, x1-tanX, y1-tanY, x1, y1)
+		}
+		px, py = x1, y1
+		ptanX, ptanY = tanX, tanY
+	}
+}
+
+// Rect creates a new rectangle shaped sub-path.
+func (p *Path) Rect(x, y, width, height float32) {
+	p.append(pathMoveTo, x, y)
+	p.append(pathLineTo, x, y+height)
+	p.append(pathLineTo, x+width, y+height)
+	p.append(pathLineTo, x+width, y)
+	p.append(pathClose)
+}
+
+// RoundedRect creates a new rounded rectangle shaped sub-path.
+func (p *Path) RoundedRect(x, y, width, height, radius float32) {
+	p.RoundedRectVarying(x, y, width, height, radius, radius, radius, radius)
+}
+
+// RoundedRectVarying creates a new rounded rectangle shaped sub-path with
+// varying radii for each corner.
+func (p *Path) RoundedRectVarying(x, y, width, height, radiusTopLeft, radiusTopRight, radiusBottomRight, radiusBottomLeft float32) {
+	if radiusTopLeft < 0.1 && radiusTopRight < 0.1 && radiusBottomRight < 0.1 && radiusBottomLeft < 0.1 {
+		p.Rect(x, y, width, height)
+		return
+	}
+	var w, h = width, height
+	var halfW, halfH = absf(w) * 0.5, absf(h) * 0.5
+	var signW, signH = signf(w), signf(h)
+	var rxBL, ryBL = minf(radiusBottomLeft, halfW) * signW, minf(radiusBottomLeft, halfH) * signH
+	var rxBR, ryBR = minf(radiusBottomRight, halfW) * signW, minf(radiusBottomRight, halfH) * signH
+	var rxTR, ryTR = minf(radiusTopRight, halfW) * signW, minf(radiusTopRight, halfH) * signH
+	var rxTL, ryTL = minf(radiusTopLeft, halfW) * signW, minf(radiusTopLeft, halfH) * signH
+	const k = 1 - kappa90
+	p.append(pathMoveTo, x, y+ryTL)
+	p.append(pathLineTo, x, y+h-ryBL)
+	p.append(pathBezierTo, x, y+h-ryBL*k, x+rxBL*k, y+h, x+rxBL, y+h)
+	p.append(pathLineTo, x+w-rxBR, y+h)
+	p.append(pathBezierTo, x+w-rxBR*k, y+h, x+w, y+h-ryBR*k, x+w, y+h-ryBR)
+	p.append(pathLineTo, x+w, y+ryTR)
+	p.append(pathBezierTo, x+w, y+ryTR*k, x+w-rxTR*k, y, x+w-rxTR, y)
+	p.append(pathLineTo, x+rxTL, y)
+	p.append(pathBezierTo, x+rxTL*k, y, x, y+ryTL*k, x, y+ryTL)
+	p.append(pathClose)
+}
+
+// Ellipse creates a new ellipse shape sub-path. The center is at (x,y).
+func (p *Path) Ellipse(x, y, radiusX, radiusY float32) {
+	var kx, ky = radiusX * kappa90, radiusY * kappa90
+	p.append(pathMoveTo, x-radiusX, y)
+	p.append(pathBezierTo, x-radiusX, y+ky, x-kx, y+radiusY, x, y+radiusY)
+	p.append(pathBezierTo, x+kx, y+radiusY, x+radiusX, y+ky, x+radiusX, y)
+	p.append(pathBezierTo, x+radiusX, y-ky, x+kx, y-radiusY, x, y-radiusY)
+	p.append(pathBezierTo, x-kx, y-radiusY, x-radiusX, y-ky, x-radiusX, y)
+	p.append(pathClose)
+}
+
+// Circle creates a new circle shaped sub-path. The center is at (x,y).
+func (p *Path) Circle(x, y, radius float32) {
+	p.Ellipse(x, y, radius, radius)
+}
+
+// AppendPath appends the commands of path src to the path.
+func (p *Path) AppendPath(src *Path) {
+	if len(src.commands) == 0 {
+		return
+	}
+	p.commands = append(p.commands, src.commands...)
+	p.lastX, p.lastY = src.lastX, src.lastY
+}
+
+// Transform transforms all points of the path by xform. The result is the
+// same as appending the path to a Context after multiplying its current
+// transform by xform.
+func (p *Path) Transform(xform [6]float32) {
+	var transform = func(pt []float32) {
+		var x, y = pt[0], pt[1]
+		pt[0] = x*xform[0] + y*xform[2] + xform[4]
+		pt[1] = x*xform[1] + y*xform[3] + xform[5]
+	}
+	p.walk(func(cmd int, args []float32) {
+		for i := 0; i+1 < len(args); i += 2 {
+			transform(args[i:])
+		}
+	})
+	if len(p.commands) > 0 {
+		var pt = []float32{p.lastX, p.lastY}
+		transform(pt)
+		p.lastX, p.lastY = pt[0], pt[1]
+	}
+}
+
+// Bounds returns the bounding box of the path as [xmin, ymin, xmax, ymax]. The
+// bounds are tight around the curves, not their control points. An empty path
+// returns zero bounds.
+func (p *Path) Bounds() [4]float32 {
+	var bounds = [4]float32{math.MaxFloat32, math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	var add = func(x, y float32) {
+		bounds[0], bounds[1] = minf(bounds[0], x), minf(bounds[1], y)
+		bounds[2], bounds[3] = maxf(bounds[2], x), maxf(bounds[3], y)
+	}
+	var x0, y0 float32
+	var empty = true
+	p.walk(func(cmd int, args []float32) {
+		switch cmd {
+		case pathMoveTo, pathLineTo:
+			x0, y0 = args[0], args[1]
+			add(x0, y0)
+			empty = false
+		case pathBezierTo:
+			var x1, y1, x2, y2, x3, y3 = args[0], args[1], args[2], args[3], args[4], args[5]
+			add(x3, y3)
+			for _, t := range cubicExtrema(x0, x1, x2, x3) {
+				add(cubicAt(x0, x1, x2, x3, t), cubicAt(y0, y1, y2, y3, t))
+			}
+			for _, t := range cubicExtrema(y0, y1, y2, y3) {
+				add(cubicAt(x0, x1, x2, x3, t), cubicAt(y0, y1, y2, y3, t))
+			}
+			x0, y0 = x3, y3
+			empty = false
+		}
+	})
+	if empty {
+		return [4]float32{}
+	}
+	return bounds
+}
+
+// Flatten returns the sub-paths of the path flattened like Context.Fill() and
+// Context.Stroke() flatten the current path, but without transforming them.
+// Curves are subdivided into line segments until they are within tolerance,
+// which is 0.25 for a Context with a device pixel ratio of 1. The points of
+// each sub-path are ordered to match its PathWinding(). Only Points, Closed
+// and Winding of the returned paths are set.
+func (p *Path) Flatten(tolerance float32) []FlatPath {
+	var distTol = tolerance * (pathDistTol / 0.25)
+	var paths []FlatPath
+	var addPoint = func(x, y float32) {
+		if len(paths) == 0 {
+			return
+		}
+		var path = &paths[len(paths)-1]
+		if n := len(path.Points); n > 0 && ptEquals(path.Points[n-1][0], path.Points[n-1][1], x, y, distTol) {
+			return
+		}
+		path.Points = append(path.Points, [2]float32{x, y})
+	}
+	var tessellateBezier func(x1, y1, x2, y2, x3, y3, x4, y4 float32, level int)
+	tessellateBezier = func(x1, y1, x2, y2, x3, y3, x4, y4 float32, level int) {
+		if level > 10 {
+			return
+		}
+		var dx, dy = x4 - x1, y4 - y1
+		var d2 = absf((x2-x4)*dy - (y2-y4)*dx)
+		var d3 = absf((x3-x4)*dy - (y3-y4)*dx)
+		if (d2+d3)*(d2+d3) < tolerance*(dx*dx+dy*dy) {
+			addPoint(x4, y4)
+			return
+		}
+		var x12, y12 = (x1 + x2) * 0.5, (y1 + y2) * 0.5
+		var x23, y23 = (x2 + x3) * 0.5, (y2 + y3) * 0.5
+		var x34, y34 = (x3 + x4) * 0.5, (y3 + y4) * 0.5
+		var x123, y123 = (x12 + x23) * 0.5, (y12 + y23) * 0.5
+		var x234, y234 = (x23 + x34) * 0.5, (y23 + y34) * 0.5
+		var x1234, y1234 = (x123 + x234) * 0.5, (y123 + y234) * 0.5
+		tessellateBezier(x1, y1, x12, y12, x123, y123, x1234, y1234, level+1)
+		tessellateBezier(x1234, y1234, x234, y234, x34, y34, x4, y4, level+1)
+	}
+
+	p.walk(func(cmd int, args []float32) {
+		switch cmd {
+		case pathMoveTo:
+			paths = append(paths, FlatPath{Winding: CCW})
+			addPoint(args[0], args[1])
+		case pathLineTo:
+			addPoint(args[0], args[1])
+		case pathBezierTo:
+			if len(paths) > 0 && len(paths[len(paths)-1].Points) > 0 {
+				var pts = paths[len(paths)-1].Points
+				var last = pts[len(pts)-1]
+				tessellateBezier(last[0], last[1], args[0], args[1], args[2], args[3], args[4], args[5], 0)
+			}
+		case pathClose:
+			if len(paths) > 0 {
+				paths[len(paths)-1].Closed = true
+			}
+		case pathWinding:
+			if len(paths) > 0 {
+				paths[len(paths)-1].Winding = Winding(args[0])
+			}
+		}
+	})
+
+	for i := range paths {
+		var path = &paths[i]
+		var pts = path.Points
+		// If the first and last points are the same, remove the last, and
+		// mark the path as closed.
+		if n := len(pts); n > 1 && ptEquals(pts[n-1][0], pts[n-1][1], pts[0][0], pts[0][1], distTol) {
+			pts = pts[:n-1]
+			path.Points = pts
+			path.Closed = true
+		}
+		// Enforce winding. The area is positive for paths which are
+		// counter-clockwise on screen, where y points down.
+		if len(pts) > 2 {
+			var area float32
+			for j := 2; j < len(pts); j++ {
+				var abx, aby = pts[j-1][0] - pts[0][0], pts[j-1][1] - pts[0][1]
+				var acx, acy = pts[j][0] - pts[0][0], pts[j][1] - pts[0][1]
+				area += acx*aby - abx*acy
+			}
+			if (path.Winding == CCW && area < 0) || (path.Winding == CW && area > 0) {
+				for j, k := 0, len(pts)-1; j < k; j, k = j+1, k-1 {
+					pts[j], pts[k] = pts[k], pts[j]
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// walk calls fn with each command of the path and its arguments.
+func (p *Path) walk(fn func(cmd int, args []float32)) {
+	for i := 0; i < len(p.commands); {
+		var cmd = int(p.commands[i])
+		var n int
+		switch cmd {
+		case pathMoveTo, pathLineTo:
+			n = 2
+		case pathBezierTo:
+			n = 6
+		case pathWinding:
+			n = 1
+		}
+		fn(cmd, p.commands[i+1:i+1+n])
+		i += 1 + n
+	}
+}
+
+// AppendPath appends the commands of p to the current path. The points of p
+// are transformed by the current transform.
+func (ctx *Context) AppendPath(p *Path) {
+	if p == nil || len(p.commands) == 0 {
+		return
+	}
+	C.nvgAppendPath(ctx.c(), (*C.float)(unsafe.Pointer(&p.commands[0])), C.int(len(p.commands)))
+}
+
+// cubicAt returns the coordinate of a cubic bezier at t.
+func cubicAt(p0, p1, p2, p3, t float32) float32 {
+	var it = 1 - t
+	return it*it*it*p0 + 3*it*it*t*p1 + 3*it*t*t*p2 + t*t*t*p3
+}
+
+// cubicExtrema returns the parameters in (0,1) where the derivative of a cubic
+// bezier coordinate is zero.
+func cubicExtrema(p0, p1, p2, p3 float32) []float32 {
+	// The derivative is a*t^2 + b*t + c.
+	var a = float64(-p0 + 3*p1 - 3*p2 + p3)
+	var b = float64(2 * (p0 - 2*p1 + p2))
+	var c = float64(p1 - p0)
+	var roots []float32
+	var add = func(t float64) {
+		if t > 0 && t < 1 {
+			roots = append(roots, float32(t))
+		}
+	}
+	if math.Abs(a) < 1e-12 {
+		if math.Abs(b) > 1e-12 {
+			add(-c / b)
+		}
+		return roots
+	}
+	var d = b*b - 4*a*c
+	if d < 0 {
+		return roots
+	}
+	d = math.Sqrt(d)
+	add((-b + d) / (2 * a))
+	add((-b - d) / (2 * a))
+	return roots
+}
+
+func ptEquals(x1, y1, x2, y2, tol float32) bool {
+	var dx, dy = x2 - x1, y2 - y1
+	return dx*dx+dy*dy < tol*tol
+}
+
+// distPtSeg returns the squared distance from point (x,y) to segment
+// (px,py)-(qx,qy).
+func distPtSeg(x, y, px, py, qx, qy float32) float32 {
+	var pqx, pqy = qx - px, qy - py
+	var dx, dy = x - px, y - py
+	var d = pqx*pqx + pqy*pqy
+	var t = pqx*dx + pqy*dy
+	if d > 0 {
+		t /= d
+	}
+	t = clampf(t, 0, 1)
+	dx = px + t*pqx - x
+	dy = py + t*pqy - y
+	return dx*dx + dy*dy
+}
+
+func normalize(x, y float32) (float32, float32) {
+	var d = float32(math.Sqrt(float64(x*x + y*y)))
+	if d > 1e-6 {
+		return x / d, y / d
+	}
+	return x, y
+}
+
+func atan2f(y, x float32) float32 {
+	return float32(math.Atan2(float64(y), float64(x)))
+}
+
+func signf(a float32) float32 {
+	if a >= 0 {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// pathBuilder has the path functions shared by Path and Context.
+type pathBuilder interface {
+	MoveTo(x, y float32)
+	LineTo(x, y float32)
+	BezierTo(c1X, c1Y, c2X, c2Y, x, y float32)
+	QuadTo(cX, cY, x, y float32)
+	ArcTo(x1, y1, x2, y2, radius float32)
+	Arc(x, y, radius, angle0, angle1 float32, direction Winding)
+	ClosePath()
+	PathWinding(direction Winding)
+	Rect(x, y, width, height float32)
+	RoundedRect(x, y, width, height, radius float32)
+	RoundedRectVarying(x, y, width, height, radiusTopLeft, radiusTopRight, radiusBottomRight, radiusBottomLeft float32)
+	Ellipse(x, y, radiusX, radiusY float32)
+	Circle(x, y, radius float32)
+}
+
+var pathTests = []struct {
+	name  string
+	build func(p pathBuilder)
+}{
+	{"Rect", func(p pathBuilder) {
+		p.Rect(10, 20, 60, 40)
+	}},
+	{"Lines", func(p pathBuilder) {
+		p.MoveTo(10, 10)
+		p.LineTo(90, 30)
+		p.LineTo(40, 90)
+		p.ClosePath()
+	}},
+	{"BezierTo", func(p pathBuilder) {
+		p.MoveTo(10, 80)
+		p.BezierTo(10, 0, 90, 0, 90, 80)
+		p.ClosePath()
+	}},
+	{"QuadTo", func(p pathBuilder) {
+		p.MoveTo(10, 80)
+		p.QuadTo(50, -20, 90, 80)
+		p.QuadTo(50, 50, 10, 80)
+	}},
+	{"ArcCW", func(p pathBuilder) {
+		p.Arc(50, 50, 35, 0, math.Pi*1.5, CW)
+		p.ClosePath()
+	}},
+	{"ArcCCW", func(p pathBuilder) {
+		p.MoveTo(50, 50)
+		p.Arc(50, 50, 35, -math.Pi/4, math.Pi/2, CCW)
+		p.ClosePath()
+	}},
+	{"ArcFullCircle", func(p pathBuilder) {
+		p.Arc(50, 50, 30, 1, 1+math.Pi*3, CW)
+	}},
+	{"ArcTo", func(p pathBuilder) {
+		p.MoveTo(10, 90)
+		p.ArcTo(10, 10, 90, 10, 30)
+		p.ArcTo(90, 90, 10, 90, 20)
+		p.ClosePath()
+	}},
+	{"ArcToDegenerate", func(p pathBuilder) {
+		p.MoveTo(10, 90)
+		p.ArcTo(10, 10, 10, 10, 30)
+		p.ArcTo(90, 10, 90, 10, 0)
+		p.LineTo(90, 90)
+	}},
+	{"RoundedRect", func(p pathBuilder) {
+		p.RoundedRect(10, 10, 80, 60, 15)
+	}},
+	{"RoundedRectVarying", func(p pathBuilder) {
+		p.RoundedRectVarying(10, 10, 80, 80, 0, 10, 30, 60)
+	}},
+	{"RoundedRectVaryingNegative", func(p pathBuilder) {
+		p.RoundedRectVarying(90, 90, -80, -60, 5, 20, 0, 40)
+	}},
+	{"Ellipse", func(p pathBuilder) {
+		p.Ellipse(50, 50, 40, 20)
+	}},
+	{"CircleHole", func(p pathBuilder) {
+		p.Circle(50, 50, 40)
+		p.Circle(50, 50, 20)
+		p.PathWinding(Hole)
+	}},
+}
+
+// renderPath fills and strokes a path drawn by fn with the software renderer.
+func renderPath(t *testing.T, fn func(ctx *Context)) *image.RGBA {
+	t.Helper()
+	return renderSoftware(t, Antialias, func(ctx *Context) {
+		ctx.BeginPath()
+		fn(ctx)
+		ctx.FillColor(red)
+		ctx.Fill()
+		ctx.StrokeColor(blue)
+		ctx.StrokeWidth(3)
+		ctx.Stroke()
+	})
+}
+
+// checkImagesNear reports the pixels of got which differ from want by more
+// than 2 in a component.
+func checkImagesNear(t *testing.T, got, want *image.RGBA) {
+	t.Helper()
+	var near = func(a, b uint8) bool {
+		var d = int(a) - int(b)
+		return d >= -2 && d <= 2
+	}
+	var diffs = 0
+	for y := got.Rect.Min.Y; y < got.Rect.Max.Y; y++ {
+		for x := got.Rect.Min.X; x < got.Rect.Max.X; x++ {
+			var g, w = got.RGBAAt(x, y), want.RGBAAt(x, y)
+			if !near(g.R, w.R) || !near(g.G, w.G) || !near(g.B, w.B) || !near(g.A, w.A) {
+				if diffs < 5 {
+					t.Errorf("pixel (%d,%d) = %v, want %v", x, y, g, w)
+				}
+				diffs++
+			}
+		}
+	}
+	if diffs > 5 {
+		t.Errorf("%d pixels differ", diffs)
+	}
+}
+
+func TestPathAppendPath(t *testing.T) {
+	for _, test := range pathTests {
+		t.Run(test.name, func(t *testing.T) {
+			var path = &Path{}
+			test.build(path)
+			var got = renderPath(t, func(ctx *Context) {
+				ctx.AppendPath(path)
+			})
+			var want = renderPath(t, func(ctx *Context) {
+				test.build(ctx)
+			})
+			checkImagesNear(t, got, want)
+		})
+	}
+}
+
+func TestPathTransform(t *testing.T) {
+	var xform [6]float32
+	TransformRotate(&xform, 0.3)
+	var scale [6]float32
+	TransformScale(&scale, 0.8, 1.2)
+	TransformMultiply(&xform, scale)
+	var translate [6]float32
+	TransformTranslate(&translate, 20, -5)
+	TransformMultiply(&xform, translate)
+	for _, test := range pathTests {
+		t.Run(test.name, func(t *testing.T) {
+			var path = &Path{}
+			test.build(path)
+			path.Transform(xform)
+			var got = renderPath(t, func(ctx *Context) {
+				ctx.AppendPath(path)
+			})
+			var want = renderPath(t, func(ctx *Context) {
+				ctx.Transform(xform[0], xform[1], xform[2], xform[3], xform[4], xform[5])
+				test.build(ctx)
+			})
+			checkImagesNear(t, got, want)
+		})
+	}
+}
+
+func TestPathTransformPoints(t *testing.T) {
+	var path = &Path{}
+	path.MoveTo(1, 2)
+	path.BezierTo(3, 4, 5, 6, 7, 8)
+	path.PathWinding(Hole)
+	path.ClosePath()
+	path.Transform([6]float32{2, 0, 0, 3, 10, 20})
+	var want = &Path{}
+	want.MoveTo(12, 26)
+	want.BezierTo(16, 32, 20, 38, 24, 44)
+	want.PathWinding(Hole)
+	want.ClosePath()
+	if !commandsNear(path.commands, want.commands) {
+		t.Errorf("transformed commands = %v, want %v", path.commands, want.commands)
+	}
+	// Relative commands continue from the transformed last point.
+	path.QuadTo(24, 50, 30, 50)
+	want.QuadTo(24, 50, 30, 50)
+	if !commandsNear(path.commands, want.commands) {
+		t.Errorf("commands after QuadTo = %v, want %v", path.commands, want.commands)
+	}
+}
+
+func TestPathBounds(t *testing.T) {
+	var tests = []struct {
+		name  string
+		build func(p *Path)
+		want  [4]float32
+	}{
+		{"Empty", func(p *Path) {}, [4]float32{}},
+		{"Rect", func(p *Path) { p.Rect(10, 20, 30, 40) }, [4]float32{10, 20, 40, 60}},
+		{"Circle", func(p *Path) { p.Circle(50, 40, 20) }, [4]float32{30, 20, 70, 60}},
+		// The control points are outside the curve.
+		{"BezierTo", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.BezierTo(0, 40, 40, 40, 40, 0)
+		}, [4]float32{0, 0, 40, 30}},
+		{"HalfCircle", func(p *Path) { p.Arc(0, 0, 10, 0, math.Pi, CW) }, [4]float32{-10, 0, 10, 10}},
+		{"Transformed", func(p *Path) {
+			p.Rect(0, 0, 10, 20)
+			p.Transform([6]float32{0, 1, -1, 0, 5, 5})
+		}, [4]float32{-15, 5, 5, 15}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var path = &Path{}
+			test.build(path)
+			var got = path.Bounds()
+			for i := range got {
+				if math.Abs(float64(got[i]-test.want[i])) > 0.01 {
+					t.Errorf("Bounds() = %v, want %v", got, test.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPathFlatten(t *testing.T) {
+	for _, test := range pathTests {
+		t.Run(test.name, func(t *testing.T) {
+			var path = &Path{}
+			test.build(path)
+			var got = path.Flatten(0.25)
+			var want []FlatPath
+			renderSoftware(t, Antialias, func(ctx *Context) {
+				ctx.BeginPath()
+				test.build(ctx)
+				want = ctx.FlattenCurrentPath()
+			})
+			if len(got) != len(want) {
+				t.Fatalf("Flatten() returned %d paths, want %d", len(got), len(want))
+			}
+			for i := range got {
+				if got[i].Closed != want[i].Closed || got[i].Winding != want[i].Winding || len(got[i].Points) != len(want[i].Points) {
+					t.Errorf("path %d has %d points, closed %v, winding %v, want %d points, closed %v, winding %v", i,
+						len(got[i].Points), got[i].Closed, got[i].Winding, len(want[i].Points), want[i].Closed, want[i].Winding)
+					continue
+				}
+				for j, pt := range got[i].Points {
+					var w = want[i].Points[j]
+					if math.Abs(float64(pt[0]-w[0])) > 1e-3 || math.Abs(float64(pt[1]-w[1])) > 1e-3 {
+						t.Errorf("path %d point %d = %v, want %v", i, j, pt, w)
+						break
+					}
+				}
+			}
+		})
+	}
+}
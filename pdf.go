@@ -0,0 +1,760 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// CreatePDFContext creates a NanoVGo context which writes a PDF document to
+// w. Each frame drawn between Context.BeginFrame() and Context.EndFrame()
+// becomes a page of the window size, with one unit being one point (1/72
+// inch). The document is completed when the context is deleted.
+//
+// Paths are written as vector outlines, linear and radial gradients as PDF
+// shadings, and text with the fonts created by Context.CreateFont() or
+// Context.CreateFontMem() embedded. Images created by Context.CreateImage()
+// and the other image constructors are embedded once per update. Other
+// gradients are rasterized at 300 dpi. The Antialias flag is ignored, as the
+// viewer anti-aliases the document.
+//
+// Errors writing to w stop the output. To check for them, create the context
+// with a PDFRenderer and check its Err() after deleting the context.
+//
+// Returns nil if the context cannot be created.
+func CreatePDFContext(w io.Writer, flags CreateFlag) *Context {
+	return CreateContextWithRenderer(NewPDFRenderer(w), flags&^Antialias)
+}
+
+// pdfRasterScale is the resolution of rasterized paints, in pixels per point.
+const pdfRasterScale = 300.0 / 72
+
+// PDFRenderer is a Renderer which writes a PDF document, see
+// CreatePDFContext(). It is used directly to check for write errors:
+//
+//     var pdf = nanovgo.NewPDFRenderer(file)
+//     var ctx = nanovgo.CreateContextWithRenderer(pdf, 0)
+//     // Draw the pages.
+//     ctx.Delete()
+//     if err := pdf.Err(); err != nil {
+//         log.Fatal(err)
+//     }
+type PDFRenderer struct {
+	// textures keeps the textures of the context.
+	textures *Recorder
+	doc      *pdfWriter
+	pagesRef int
+	pages    []int
+	fonts    map[*textFont]*pdfFont
+	// images maps textures to their image objects, until they are updated.
+	images map[int]int
+	// coloredImages maps masks and colors to one pixel images using them as
+	// soft mask.
+	coloredImages map[string]int
+	// tintedImages maps images and tints to images multiplied by the tint.
+	tintedImages map[string]int
+
+	// The page being drawn.
+	width, height float32
+	content       bytes.Buffer
+	resources     map[string][]string
+	resourceNames map[string]string
+}
+
+// pdfFont is a font embedded in the document, with the glyphs used.
+type pdfFont struct {
+	ref    int
+	font   *textFont
+	glyphs map[int]textGlyph
+}
+
+// NewPDFRenderer creates a PDFRenderer which writes a PDF document to w. The
+// document is completed when the context is deleted.
+func NewPDFRenderer(w io.Writer) *PDFRenderer {
+	var r = &PDFRenderer{
+		textures:      NewRecorder(),
+		doc:           &pdfWriter{w: w},
+		fonts:         make(map[*textFont]*pdfFont),
+		images:        make(map[int]int),
+		coloredImages: make(map[string]int),
+		tintedImages:  make(map[string]int),
+	}
+	r.doc.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	r.pagesRef = r.doc.newObject()
+	r.resetPage()
+	return r
+}
+
+// Err returns the first error which occurred when writing the document.
+func (r *PDFRenderer) Err() error {
+	return r.doc.err
+}
+
+// CreateTexture implements Renderer.
+func (r *PDFRenderer) CreateTexture(typ TextureType, width, height int, imageFlags ImageFlag, data []byte) int {
+	return r.textures.CreateTexture(typ, width, height, imageFlags, data)
+}
+
+// DeleteTexture implements Renderer.
+func (r *PDFRenderer) DeleteTexture(image int) bool {
+	delete(r.images, image)
+	return r.textures.DeleteTexture(image)
+}
+
+// UpdateTexture implements Renderer.
+func (r *PDFRenderer) UpdateTexture(image, x, y, width, height int, data []byte) bool {
+	delete(r.images, image)
+	return r.textures.UpdateTexture(image, x, y, width, height, data)
+}
+
+// TextureSize implements Renderer.
+func (r *PDFRenderer) TextureSize(image int) (width, height int, ok bool) {
+	return r.textures.TextureSize(image)
+}
+
+// Viewport implements Renderer. It starts a new page.
+func (r *PDFRenderer) Viewport(width, height, devicePixelRatio float32) {
+	r.resetPage()
+	r.width, r.height = width, height
+}
+
+// Cancel implements Renderer. It discards the page being drawn.
+func (r *PDFRenderer) Cancel() {
+	r.resetPage()
+}
+
+// Flush implements Renderer. It writes the page being drawn.
+func (r *PDFRenderer) Flush() {
+	var contentRef = r.doc.newObject()
+	var content = fmt.Sprintf("1 0 0 -1 0 %s cm\n", formatNumber(r.height)) + r.content.String()
+	r.doc.stream(contentRef, "", []byte(content))
+
+	var categories = make([]string, 0, len(r.resources))
+	for category := range r.resources {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	var resources strings.Builder
+	for _, category := range categories {
+		fmt.Fprintf(&resources, " /%s << %s >>", category, strings.Join(r.resources[category], " "))
+	}
+
+	var pageRef = r.doc.newObject()
+	r.doc.object(pageRef, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Group << /S /Transparency /CS /DeviceRGB >> /Resources <<%s >> /Contents %d 0 R >>",
+		r.pagesRef, formatNumber(r.width), formatNumber(r.height), resources.String(), contentRef)
+	r.pages = append(r.pages, pageRef)
+	r.resetPage()
+}
+
+// Delete implements Renderer. It writes the fonts and completes the document.
+func (r *PDFRenderer) Delete() {
+	var fonts = make([]*pdfFont, 0, len(r.fonts))
+	for _, font := range r.fonts {
+		fonts = append(fonts, font)
+	}
+	sort.Slice(fonts, func(i, j int) bool { return fonts[i].ref < fonts[j].ref })
+	for _, font := range fonts {
+		r.writeFont(font)
+	}
+
+	var kids = make([]string, len(r.pages))
+	for i, page := range r.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	r.doc.object(r.pagesRef, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(r.pages))
+	var catalogRef = r.doc.newObject()
+	r.doc.object(catalogRef, "<< /Type /Catalog /Pages %d 0 R >>", r.pagesRef)
+	r.doc.close(catalogRef)
+	r.textures.Delete()
+}
+
+func (r *PDFRenderer) resetPage() {
+	r.content.Reset()
+	r.resources = make(map[string][]string)
+	r.resourceNames = make(map[string]string)
+}
+
+func (r *PDFRenderer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&r.content, format, args...)
+}
+
+// resource adds value to the resources of category of the page, and returns
+// its name. Equal values share a name.
+func (r *PDFRenderer) resource(category, value string) string {
+	var key = category + " " + value
+	if name, ok := r.resourceNames[key]; ok {
+		return name
+	}
+	var name = pdfResourcePrefixes[category] + strconv.Itoa(len(r.resourceNames))
+	r.resourceNames[key] = name
+	r.resources[category] = append(r.resources[category], "/"+name+" "+value)
+	return name
+}
+
+var pdfResourcePrefixes = map[string]string{
+	"ExtGState": "GS",
+	"Font":      "F",
+	"Pattern":   "P",
+	"Shading":   "Sh",
+	"XObject":   "Im",
+}
+
+func pdfMatrix(xform [6]float32) string {
+	return fmt.Sprintf("%s %s %s %s %s %s",
+		formatNumber(xform[0]), formatNumber(xform[1]), formatNumber(xform[2]),
+		formatNumber(xform[3]), formatNumber(xform[4]), formatNumber(xform[5]))
+}
+
+// pdfRGB returns the color of c, which is not premultiplied.
+func pdfRGB(c [4]float32) string {
+	return fmt.Sprintf("%s %s %s", formatNumber(clampf(c[0], 0, 1)), formatNumber(clampf(c[1], 0, 1)), formatNumber(clampf(c[2], 0, 1)))
+}
+
+// pdfBlendMode returns the blend mode matching blend, or an empty string for
+// normal blending and composite operations PDF cannot express.
+func pdfBlendMode(blend BlendState) string {
+	switch {
+	case blend.SrcRGB == DstColor && blend.DstRGB == OneMinusSrcAlpha:
+		return "Multiply"
+	case blend.SrcRGB == One && blend.DstRGB == OneMinusSrcColor:
+		return "Screen"
+	}
+	return ""
+}
+
+// pdfShape is a path or text which is filled with a paint.
+type pdfShape struct {
+	// fill fills the shape with the current fill color.
+	fill string
+	// clip intersects the clipping path with the shape.
+	clip string
+	// bounds are the bounds of the shape on the page.
+	bounds [4]float32
+}
+
+// Fill implements Renderer.
+func (r *PDFRenderer) Fill(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath) {
+	var d strings.Builder
+	for _, path := range paths {
+		writePolygon(&d, pdfPolygonFormat, path.Fill)
+	}
+	r.draw(paint, op, scissor, pdfPathShape(d.String(), bounds))
+}
+
+// Stroke implements Renderer. The strokes are written as the outlines of their
+// triangle strips, see writeStripOutline().
+func (r *PDFRenderer) Stroke(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, fringe, strokeWidth float32, paths []RenderPath) {
+	var d strings.Builder
+	var bounds = emptyBounds()
+	for _, path := range paths {
+		writeStripOutline(&d, pdfPolygonFormat, path.Stroke, path.Closed)
+		for _, v := range path.Stroke {
+			bounds = addBounds(bounds, v.X, v.Y)
+		}
+	}
+	r.draw(paint, op, scissor, pdfPathShape(d.String(), bounds))
+}
+
+// Triangles implements Renderer. NanoVG draws text with triangles, which
+// PDFRenderer draws with fonts instead, so this is only a fallback which
+// draws the triangles with the texture as a soft mask.
+func (r *PDFRenderer) Triangles(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, verts []Vertex) {
+	var tex = r.textures.Texture(paint.Image)
+	if tex == nil {
+		return
+	}
+	r.printf("q\n")
+	r.writeScissor(scissor)
+	if mode := pdfBlendMode(recordBlend(op)); mode != "" {
+		r.printf("/%s gs\n", r.resource("ExtGState", "<< /BM /"+mode+" >>"))
+	}
+	var img = r.resource("XObject", fmt.Sprintf("%d 0 R", r.coloredImage(paint.Image, paint.InnerColor)))
+	// Image space is the unit square from the bottom, which is mapped to
+	// texture pixels and then to the page with the transform of each
+	// triangle.
+	var toTexture = [6]float32{float32(tex.Width), 0, 0, -float32(tex.Height), 0, float32(tex.Height)}
+	for i := 2; i < len(verts); i += 3 {
+		var tri = [3]Vertex{verts[i-2], verts[i-1], verts[i]}
+		for j := range tri {
+			tri[j].U *= float32(tex.Width)
+			tri[j].V *= float32(tex.Height)
+		}
+		var xform, ok = textureXform(tri)
+		if !ok {
+			continue
+		}
+		var m = toTexture
+		TransformMultiply(&m, xform)
+		var d strings.Builder
+		writePolygon(&d, pdfPolygonFormat, tri[:])
+		r.printf("q\n%sW n\n%s cm\n/%s Do\nQ\n", d.String(), pdfMatrix(m), img)
+	}
+	r.printf("Q\n")
+}
+
+// drawText implements textRenderer.
+func (r *PDFRenderer) drawText(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, run *textRun) {
+	if len(run.glyphs) == 0 {
+		return
+	}
+	var text strings.Builder
+	var bounds = emptyBounds()
+	var font *pdfFont
+	for _, glyph := range run.glyphs {
+		if font == nil || font.font != glyph.font {
+			font = r.font(glyph.font)
+			fmt.Fprintf(&text, "/%s %s Tf\n", r.resource("Font", fmt.Sprintf("%d 0 R", font.ref)), formatNumber(run.size))
+		}
+		font.glyphs[glyph.index] = glyph
+		// Text space is y up, so it is flipped back from the page.
+		var m = [6]float32{1, 0, 0, -1, glyph.x, glyph.y}
+		TransformMultiply(&m, run.xform)
+		fmt.Fprintf(&text, "%s Tm <%04x> Tj\n", pdfMatrix(m), glyph.index)
+
+		var scale = run.size / glyph.font.unitsPerEm
+		var x0, x1 = glyph.x, glyph.x + float32(glyph.advance)*scale
+		var y0, y1 = glyph.y - float32(glyph.font.ascent)*scale, glyph.y - float32(glyph.font.descent)*scale
+		for _, p := range [][2]float32{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}} {
+			var x, y = transformPoint(run.xform, p[0], p[1])
+			bounds = addBounds(bounds, x, y)
+		}
+	}
+	r.draw(paint, op, scissor, pdfShape{
+		fill:   "BT\n" + text.String() + "ET\n",
+		clip:   "BT\n7 Tr\n" + text.String() + "ET\n",
+		bounds: bounds,
+	})
+}
+
+func pdfPathShape(d string, bounds [4]float32) pdfShape {
+	return pdfShape{fill: d + "f\n", clip: d + "W n\n", bounds: bounds}
+}
+
+var pdfPolygonFormat = polygonFormat{moveTo: "%s %s m\n", lineTo: "%s %s l\n", close: "h\n"}
+
+func emptyBounds() [4]float32 {
+	return [4]float32{float32(math.Inf(1)), float32(math.Inf(1)), float32(math.Inf(-1)), float32(math.Inf(-1))}
+}
+
+func addBounds(bounds [4]float32, x, y float32) [4]float32 {
+	return [4]float32{minf(bounds[0], x), minf(bounds[1], y), maxf(bounds[2], x), maxf(bounds[3], y)}
+}
+
+func (r *PDFRenderer) writeScissor(s *Scissor) {
+	if s.Extent[0] < -0.5 || s.Extent[1] < -0.5 {
+		return
+	}
+	r.printf("%s cm\n%s %s %s %s re W n\n%s cm\n", pdfMatrix(s.Xform),
+		formatNumber(-s.Extent[0]), formatNumber(-s.Extent[1]), formatNumber(2*s.Extent[0]), formatNumber(2*s.Extent[1]),
+		pdfMatrix(inverseXform(s.Xform)))
+}
+
+// draw fills shape with paint.
+func (r *PDFRenderer) draw(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, shape pdfShape) {
+	if shape.fill == "" || !(shape.bounds[0] <= shape.bounds[2]) {
+		return
+	}
+	r.printf("q\n")
+	r.writeScissor(scissor)
+	var state []string
+	if mode := pdfBlendMode(recordBlend(op)); mode != "" {
+		state = append(state, "/BM /"+mode)
+	}
+	var setState = func(alpha float32) {
+		if alpha = clampf(alpha, 0, 1); alpha < 1 {
+			state = append(state, "/ca "+formatNumber(alpha))
+		}
+		if len(state) > 0 {
+			r.printf("/%s gs\n", r.resource("ExtGState", "<< "+strings.Join(state, " ")+" >>"))
+		}
+	}
+
+	var sameAlpha = paint.InnerColor[3] == paint.OuterColor[3]
+	for _, stop := range paint.Stops {
+		sameAlpha = sameAlpha && stop.Color[3] == paint.Stops[0].Color[3]
+	}
+	switch {
+	case paint.Gradient != GradientNone && len(paint.Stops) == 0:
+	case paint.Gradient != GradientNone && paint.Gradient != GradientConic && sameAlpha:
+		setState(paint.InnerColor[3] * paint.Stops[0].Color[3])
+		r.writeGradientStops(paint, shape)
+	case paint.Gradient != GradientNone:
+		setState(1)
+		r.writeRasterizedPaint(paint, shape)
+	case paint.Image != 0 && r.textures.Texture(paint.Image) != nil:
+		setState(paint.InnerColor[3])
+		r.printf("/Pattern cs /%s scn\n%s", r.resource("Pattern", fmt.Sprintf("%d 0 R", r.imagePattern(paint))), shape.fill)
+	case paint.InnerColor == paint.OuterColor || paint.Image != 0:
+		setState(paint.InnerColor[3])
+		r.printf("%s rg\n%s", pdfRGB(paint.InnerColor), shape.fill)
+	case sameAlpha && paint.Radius == 0 && paint.Extent[0] >= 1e4:
+		setState(paint.InnerColor[3])
+		r.writeLinearGradient(paint, shape)
+	case sameAlpha && paint.Extent[0] == paint.Extent[1] && paint.Radius == paint.Extent[0]:
+		setState(paint.InnerColor[3])
+		r.writeRadialGradient(paint, shape)
+	default:
+		setState(1)
+		r.writeRasterizedPaint(paint, shape)
+	}
+	r.printf("Q\n")
+}
+
+func pdfFunction(paint *RenderPaint, t0, t1 float32) string {
+	var c0, c1 [4]float32
+	for i := range c0 {
+		c0[i] = paint.InnerColor[i]*(1-t0) + paint.OuterColor[i]*t0
+		c1[i] = paint.InnerColor[i]*(1-t1) + paint.OuterColor[i]*t1
+	}
+	return fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>", pdfRGB(c0), pdfRGB(c1))
+}
+
+func (r *PDFRenderer) writeLinearGradient(paint *RenderPaint, shape pdfShape) {
+	// The gradient runs along the y axis of the paint space, centered at
+	// Extent[1]. The paint transform is moved there to keep numbers small.
+	var xform = paint.Xform
+	xform[4] += xform[2] * paint.Extent[1]
+	xform[5] += xform[3] * paint.Extent[1]
+	var feather = maxf(paint.Feather, 1)
+	var shading = fmt.Sprintf("<< /ShadingType 2 /ColorSpace /DeviceRGB /Coords [0 %s 0 %s] /Function %s /Extend [true true] >>",
+		formatNumber(-feather*0.5), formatNumber(feather*0.5), pdfFunction(paint, 0, 1))
+	r.printf("%s%s cm\n/%s sh\n", shape.clip, pdfMatrix(xform), r.resource("Shading", shading))
+}
+
+func (r *PDFRenderer) writeRadialGradient(paint *RenderPaint, shape pdfShape) {
+	// The mix ratio is 0 at Radius-Feather/2 and 1 at Radius+Feather/2. When
+	// the inner radius is negative, the gradient starts mixed at the center.
+	var feather = maxf(paint.Feather, 1)
+	var r0, r1 = paint.Radius - feather*0.5, paint.Radius + feather*0.5
+	var t0 float32
+	if r0 < 0 {
+		r0, t0 = 0, clampf((feather*0.5-paint.Radius)/feather, 0, 1)
+	}
+	var shading = fmt.Sprintf("<< /ShadingType 3 /ColorSpace /DeviceRGB /Coords [0 0 %s 0 0 %s] /Function %s /Extend [true true] >>",
+		formatNumber(r0), formatNumber(r1), pdfFunction(paint, t0, 1))
+	r.printf("%s%s cm\n/%s sh\n", shape.clip, pdfMatrix(paint.Xform), r.resource("Shading", shading))
+}
+
+// writeGradientStops draws a gradient with color stops of the same alpha.
+func (r *PDFRenderer) writeGradientStops(paint *RenderPaint, shape pdfShape) {
+	// Stitch a function for each pair of stops, and pad the stops to cover
+	// [0,1]. Hard edges are the bounds between two functions.
+	var stops = paint.Stops
+	if first := stops[0]; first.Offset > 0 {
+		stops = append([]RenderStop{{0, first.Color}}, stops...)
+	}
+	if last := stops[len(stops)-1]; last.Offset < 1 {
+		stops = append(stops, RenderStop{1, last.Color})
+	}
+	var functions, bounds, encode []string
+	for i := 1; i < len(stops); i++ {
+		var s0, s1 = stops[i-1], stops[i]
+		if s1.Offset <= s0.Offset {
+			continue
+		}
+		if len(functions) > 0 {
+			bounds = append(bounds, formatNumber(s0.Offset))
+		}
+		functions = append(functions, fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>", pdfRGB(s0.Color), pdfRGB(s1.Color)))
+		encode = append(encode, "0 1")
+	}
+	var function = fmt.Sprintf("<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>",
+		strings.Join(functions, " "), strings.Join(bounds, " "), strings.Join(encode, " "))
+
+	var shading string
+	if paint.Gradient == GradientRadial {
+		shading = fmt.Sprintf("<< /ShadingType 3 /ColorSpace /DeviceRGB /Coords [0 0 %s 0 0 %s] /Function %s /Extend [true true] >>",
+			formatNumber(paint.Radius), formatNumber(paint.Extent[0]), function)
+	} else {
+		shading = fmt.Sprintf("<< /ShadingType 2 /ColorSpace /DeviceRGB /Coords [0 0 %s 0] /Function %s /Extend [true true] >>",
+			formatNumber(paint.Extent[0]), function)
+	}
+	r.printf("%s%s cm\n/%s sh\n", shape.clip, pdfMatrix(paint.Xform), r.resource("Shading", shading))
+}
+
+// writeRasterizedPaint draws a gradient PDF cannot express as an image
+// covering the shape.
+func (r *PDFRenderer) writeRasterizedPaint(paint *RenderPaint, shape pdfShape) {
+	var img = rasterizePaint(paint, shape.bounds, pdfRasterScale)
+	if img == nil {
+		return
+	}
+	var ref = r.doc.newObject()
+	r.doc.image(ref, img)
+	var x, y = shape.bounds[0], shape.bounds[1]
+	var w, h = shape.bounds[2] - x, shape.bounds[3] - y
+	r.printf("%s%s 0 0 %s %s %s cm\n/%s Do\n", shape.clip,
+		formatNumber(w), formatNumber(-h), formatNumber(x), formatNumber(y+h), r.resource("XObject", fmt.Sprintf("%d 0 R", ref)))
+}
+
+// imagePattern writes a tiling pattern of the image of paint, and returns its
+// object.
+func (r *PDFRenderer) imagePattern(paint *RenderPaint) int {
+	// Pattern space is mapped to the page before the page is flipped.
+	var matrix = paint.Xform
+	TransformMultiply(&matrix, [6]float32{1, 0, 0, -1, 0, r.height})
+	var w, h = paint.Extent[0], paint.Extent[1]
+	var content = fmt.Sprintf("%s 0 0 %s 0 %s cm\n/Im Do\n", formatNumber(w), formatNumber(-h), formatNumber(h))
+	var ref = r.doc.newObject()
+	r.doc.stream(ref, fmt.Sprintf("/Type /Pattern /PatternType 1 /PaintType 1 /TilingType 1 /BBox [0 0 %s %s] /XStep %s /YStep %s /Matrix [%s] /Resources << /XObject << /Im %d 0 R >> >>",
+		formatNumber(w), formatNumber(h), formatNumber(w), formatNumber(h), pdfMatrix(matrix), r.tintedImage(paint.Image, paint.InnerColor)), []byte(content))
+	return ref
+}
+
+// tintedImage returns the image object of a texture with its colors
+// multiplied by the RGB components of tint.
+func (r *PDFRenderer) tintedImage(image int, tint [4]float32) int {
+	var ref = r.image(image)
+	if tint[0] == 1 && tint[1] == 1 && tint[2] == 1 {
+		return ref
+	}
+	var key = fmt.Sprintf("%d %s", ref, pdfRGB(tint))
+	if ref, ok := r.tintedImages[key]; ok {
+		return ref
+	}
+	var img = tintImage(textureImage(r.textures.Texture(image)), tint)
+	ref = r.doc.newObject()
+	r.doc.image(ref, img)
+	r.tintedImages[key] = ref
+	return ref
+}
+
+// tintImage returns src with its colors multiplied by the RGB components of
+// tint.
+func tintImage(src image.Image, tint [4]float32) *image.NRGBA {
+	var img = image.NewNRGBA(src.Bounds())
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			var c = color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+			c.R = uint8(float32(c.R)*clampf(tint[0], 0, 1) + 0.5)
+			c.G = uint8(float32(c.G)*clampf(tint[1], 0, 1) + 0.5)
+			c.B = uint8(float32(c.B)*clampf(tint[2], 0, 1) + 0.5)
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// image returns the image object of a texture, which is written once until
+// the texture is updated.
+func (r *PDFRenderer) image(image int) int {
+	if ref, ok := r.images[image]; ok {
+		return ref
+	}
+	var ref = r.doc.newObject()
+	r.doc.image(ref, textureImage(r.textures.Texture(image)))
+	r.images[image] = ref
+	return ref
+}
+
+// coloredImage returns a one pixel image of c with the texture image as soft
+// mask.
+func (r *PDFRenderer) coloredImage(image int, c [4]float32) int {
+	var mask = r.image(image)
+	var key = fmt.Sprintf("%d %s", mask, pdfRGB(c))
+	if ref, ok := r.coloredImages[key]; ok {
+		return ref
+	}
+	var ref = r.doc.newObject()
+	var pixel = color.NRGBA{uint8(clampf(c[0], 0, 1)*255 + 0.5), uint8(clampf(c[1], 0, 1)*255 + 0.5), uint8(clampf(c[2], 0, 1)*255 + 0.5), 255}
+	r.doc.stream(ref, fmt.Sprintf("/Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceRGB /BitsPerComponent 8 /SMask %d 0 R", mask),
+		[]byte{pixel.R, pixel.G, pixel.B})
+	r.coloredImages[key] = ref
+	return ref
+}
+
+// font returns the embedded font of font, which is written when the document
+// is completed.
+func (r *PDFRenderer) font(font *textFont) *pdfFont {
+	if embedded, ok := r.fonts[font]; ok {
+		return embedded
+	}
+	var embedded = &pdfFont{
+		ref:    r.doc.newObject(),
+		font:   font,
+		glyphs: make(map[int]textGlyph),
+	}
+	r.fonts[font] = embedded
+	return embedded
+}
+
+func (r *PDFRenderer) writeFont(font *pdfFont) {
+	var f = font.font
+	var name = pdfFontName(f)
+	var scale = 1000 / f.unitsPerEm
+	var indices = make([]int, 0, len(font.glyphs))
+	for index := range font.glyphs {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	var fileRef, descriptorRef, cidRef, unicodeRef = r.doc.newObject(), r.doc.newObject(), r.doc.newObject(), r.doc.newObject()
+	var subtype, fileKey, fileDict = "CIDFontType2", "FontFile2", fmt.Sprintf("/Length1 %d", len(f.data))
+	if bytes.HasPrefix(f.data, []byte("OTTO")) {
+		subtype, fileKey, fileDict = "CIDFontType0", "FontFile3", "/Subtype /OpenType"
+	}
+	r.doc.stream(fileRef, fileDict, f.data)
+	r.doc.object(descriptorRef, "<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /%s %d 0 R >>",
+		name, int(float32(f.bounds[0])*scale), int(float32(f.bounds[1])*scale), int(float32(f.bounds[2])*scale), int(float32(f.bounds[3])*scale),
+		int(float32(f.ascent)*scale), int(float32(f.descent)*scale), int(float32(f.ascent)*scale), fileKey, fileRef)
+
+	var widths strings.Builder
+	for _, index := range indices {
+		fmt.Fprintf(&widths, "%d [%d] ", index, int(float32(font.glyphs[index].advance)*scale+0.5))
+	}
+	var cidToGID string
+	if subtype == "CIDFontType2" {
+		cidToGID = " /CIDToGIDMap /Identity"
+	}
+	r.doc.object(cidRef, "<< /Type /Font /Subtype /%s /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s]%s >>",
+		subtype, name, descriptorRef, widths.String(), cidToGID)
+
+	var cmap strings.Builder
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(indices); start += 100 {
+		var end = start + 100
+		if end > len(indices) {
+			end = len(indices)
+		}
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", end-start)
+		for _, index := range indices[start:end] {
+			fmt.Fprintf(&cmap, "<%04x> <", index)
+			for _, unit := range utf16.Encode([]rune{font.glyphs[index].codepoint}) {
+				fmt.Fprintf(&cmap, "%04x", unit)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	r.doc.stream(unicodeRef, "", []byte(cmap.String()))
+
+	r.doc.object(font.ref, "<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		name, cidRef, unicodeRef)
+}
+
+// pdfFontName returns the name of font with the characters which are not
+// allowed in PDF names removed.
+func pdfFontName(font *textFont) string {
+	var name = strings.Map(func(c rune) rune {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' {
+			return c
+		}
+		return -1
+	}, font.name)
+	if name == "" {
+		name = "Font" + strconv.Itoa(font.id)
+	}
+	return name
+}
+
+// pdfWriter writes the objects of a PDF document, and keeps the first error.
+type pdfWriter struct {
+	w      io.Writer
+	offset int
+	// offsets are the offsets of the objects, by object number - 1.
+	offsets []int
+	err     error
+}
+
+func (p *pdfWriter) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	var n int
+	n, p.err = fmt.Fprintf(p.w, format, args...)
+	p.offset += n
+}
+
+// newObject reserves the number of an object, which is written later.
+func (p *pdfWriter) newObject() int {
+	p.offsets = append(p.offsets, 0)
+	return len(p.offsets)
+}
+
+func (p *pdfWriter) object(ref int, format string, args ...interface{}) {
+	p.offsets[ref-1] = p.offset
+	p.printf("%d 0 obj\n", ref)
+	p.printf(format, args...)
+	p.printf("\nendobj\n")
+}
+
+// stream writes a stream object of data compressed with zlib, with the
+// entries of dict added to the stream dictionary.
+func (p *pdfWriter) stream(ref int, dict string, data []byte) {
+	var compressed bytes.Buffer
+	var zw = zlib.NewWriter(&compressed)
+	zw.Write(data)
+	zw.Close()
+	if dict != "" {
+		dict += " "
+	}
+	p.object(ref, "<< %s/Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream", dict, compressed.Len(), compressed.Bytes())
+}
+
+// image writes an image object of img, with its alpha channel as soft mask
+// if it is not opaque. Alpha images are written as gray images, so they can be
+// used as soft masks themselves.
+func (p *pdfWriter) image(ref int, img image.Image) {
+	var bounds = img.Bounds()
+	var size = fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8", bounds.Dx(), bounds.Dy())
+	if alpha, ok := img.(*image.Alpha); ok {
+		p.stream(ref, size+" /ColorSpace /DeviceGray", alpha.Pix)
+		return
+	}
+
+	var rgb = make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	var alpha = make([]byte, 0, bounds.Dx()*bounds.Dy())
+	var opaque = true
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var c = color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 255
+		}
+	}
+	var dict = size + " /ColorSpace /DeviceRGB"
+	if !opaque {
+		var maskRef = p.newObject()
+		p.stream(maskRef, size+" /ColorSpace /DeviceGray", alpha)
+		dict += fmt.Sprintf(" /SMask %d 0 R", maskRef)
+	}
+	p.stream(ref, dict, rgb)
+}
+
+// close writes the cross-reference table and the trailer.
+func (p *pdfWriter) close(root int) {
+	var xref = p.offset
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		p.printf("%010d 00000 n \n", offset)
+	}
+	p.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, root, xref)
+}
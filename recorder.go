@@ -0,0 +1,282 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Recorder is a Renderer which records the render calls of each frame as a
+// Frame instead of drawing them. It can be used to test drawing code without
+// OpenGL, by inspecting the recorded commands or comparing them against a
+// golden file.
+//
+//     var recorder = nanovgo.NewRecorder()
+//     var ctx = nanovgo.CreateContextWithRenderer(recorder, nanovgo.Antialias)
+//     ctx.BeginFrame(width, height, 1)
+//     // Draw with ctx.
+//     ctx.EndFrame()
+//     var frame = recorder.Frame()
+type Recorder struct {
+	textures    map[int]*RecordedTexture
+	nextTexture int
+	current     Frame
+	frame       *Frame
+}
+
+// NewRecorder creates a new Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		textures: make(map[int]*RecordedTexture),
+	}
+}
+
+// Frame returns the last frame ended with Context.EndFrame(), or nil if no
+// frame was ended yet.
+func (r *Recorder) Frame() *Frame {
+	return r.frame
+}
+
+// Texture returns the texture image, or nil if it does not exist.
+func (r *Recorder) Texture(image int) *RecordedTexture {
+	return r.textures[image]
+}
+
+// Frame is the display list of a frame drawn between Context.BeginFrame() and
+// Context.EndFrame().
+type Frame struct {
+	// The window size and device pixel ratio passed to Context.BeginFrame().
+	Width, Height    float32
+	DevicePixelRatio float32
+	Commands         []Command
+}
+
+// Encode writes frame to w as indented JSON, which is suitable for golden
+// files.
+func (frame *Frame) Encode(w io.Writer) error {
+	var encoder = json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(frame)
+}
+
+// DecodeFrame reads a frame written by Frame.Encode() from r.
+func DecodeFrame(r io.Reader) (*Frame, error) {
+	var frame Frame
+	if err := json.NewDecoder(r).Decode(&frame); err != nil {
+		return nil, err
+	}
+	return &frame, nil
+}
+
+// CommandType is the type of a recorded render call.
+type CommandType int
+
+// Command types.
+const (
+	// FillCommand is recorded by Context.Fill().
+	FillCommand CommandType = iota
+	// StrokeCommand is recorded by Context.Stroke().
+	StrokeCommand
+	// TrianglesCommand is recorded by text drawing functions.
+	TrianglesCommand
+)
+
+// String returns the name of typ.
+func (typ CommandType) String() string {
+	switch typ {
+	case FillCommand:
+		return "Fill"
+	case StrokeCommand:
+		return "Stroke"
+	case TrianglesCommand:
+		return "Triangles"
+	}
+	return "Unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (typ CommandType) MarshalText() ([]byte, error) {
+	return []byte(typ.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (typ *CommandType) UnmarshalText(text []byte) error {
+	for _, t := range []CommandType{FillCommand, StrokeCommand, TrianglesCommand} {
+		if t.String() == string(text) {
+			*typ = t
+			return nil
+		}
+	}
+	return fmt.Errorf("nanovgo: unknown command type %q", text)
+}
+
+// BlendState is the composite operation of a render call as blend factors, see
+// Context.GlobalCompositeBlendFuncSeparate().
+type BlendState struct {
+	SrcRGB, DstRGB, SrcAlpha, DstAlpha BlendFactor
+}
+
+// Command is a recorded render call.
+type Command struct {
+	Type    CommandType
+	Paint   RenderPaint
+	Blend   BlendState
+	Scissor Scissor
+	Fringe  float32
+	// StrokeWidth is set for StrokeCommand.
+	StrokeWidth float32 `json:",omitempty"`
+	// Bounds are the bounds of Paths of FillCommand as
+	// [xmin, ymin, xmax, ymax].
+	Bounds [4]float32
+	// Paths are set for FillCommand and StrokeCommand.
+	Paths []RenderPath `json:",omitempty"`
+	// Vertices are set for TrianglesCommand. Each three vertices form a
+	// triangle.
+	Vertices []Vertex `json:",omitempty"`
+}
+
+// RecordedTexture is a texture created by NanoVG in a Recorder.
+type RecordedTexture struct {
+	Type          TextureType
+	Width, Height int
+	Flags         ImageFlag
+	// Data holds the pixels of the texture, tightly packed in rows from top to
+	// bottom.
+	Data []byte
+}
+
+// CreateTexture implements Renderer.
+func (r *Recorder) CreateTexture(typ TextureType, width, height int, imageFlags ImageFlag, data []byte) int {
+	var tex = &RecordedTexture{
+		Type:   typ,
+		Width:  width,
+		Height: height,
+		Flags:  imageFlags,
+		Data:   make([]byte, width*height*typ.bytesPerPixel()),
+	}
+	copy(tex.Data, data)
+	r.nextTexture++
+	r.textures[r.nextTexture] = tex
+	return r.nextTexture
+}
+
+// DeleteTexture implements Renderer.
+func (r *Recorder) DeleteTexture(image int) bool {
+	if r.textures[image] == nil {
+		return false
+	}
+	delete(r.textures, image)
+	return true
+}
+
+// UpdateTexture implements Renderer.
+func (r *Recorder) UpdateTexture(image, x, y, width, height int, data []byte) bool {
+	var tex = r.textures[image]
+	if tex == nil {
+		return false
+	}
+	var bpp = tex.Type.bytesPerPixel()
+	var rowLen = width * bpp
+	for row := 0; row < height; row++ {
+		var start = ((y+row)*tex.Width + x) * bpp
+		copy(tex.Data[start:start+rowLen], data[row*rowLen:])
+	}
+	return true
+}
+
+// TextureSize implements Renderer.
+func (r *Recorder) TextureSize(image int) (width, height int, ok bool) {
+	var tex = r.textures[image]
+	if tex == nil {
+		return 0, 0, false
+	}
+	return tex.Width, tex.Height, true
+}
+
+// Viewport implements Renderer. It starts recording a new frame.
+func (r *Recorder) Viewport(width, height, devicePixelRatio float32) {
+	r.current = Frame{
+		Width:            width,
+		Height:           height,
+		DevicePixelRatio: devicePixelRatio,
+	}
+}
+
+// Cancel implements Renderer. It discards the frame being recorded.
+func (r *Recorder) Cancel() {
+	r.current.Commands = nil
+}
+
+// Flush implements Renderer. It finishes the frame being recorded, which is
+// then returned by Recorder.Frame().
+func (r *Recorder) Flush() {
+	var frame = r.current
+	r.frame = &frame
+	r.current.Commands = nil
+}
+
+func recordBlend(op CompositeOperationState) BlendState {
+	var blend BlendState
+	blend.SrcRGB, blend.DstRGB, blend.SrcAlpha, blend.DstAlpha = op.BlendFactors()
+	return blend
+}
+
+// Fill implements Renderer.
+func (r *Recorder) Fill(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath) {
+	r.current.Commands = append(r.current.Commands, Command{
+		Type:    FillCommand,
+		Paint:   *paint,
+		Blend:   recordBlend(op),
+		Scissor: *scissor,
+		Fringe:  fringe,
+		Bounds:  bounds,
+		Paths:   paths,
+	})
+}
+
+// Stroke implements Renderer.
+func (r *Recorder) Stroke(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, fringe, strokeWidth float32, paths []RenderPath) {
+	r.current.Commands = append(r.current.Commands, Command{
+		Type:        StrokeCommand,
+		Paint:       *paint,
+		Blend:       recordBlend(op),
+		Scissor:     *scissor,
+		Fringe:      fringe,
+		StrokeWidth: strokeWidth,
+		Paths:       paths,
+	})
+}
+
+// Triangles implements Renderer.
+func (r *Recorder) Triangles(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, verts []Vertex) {
+	r.current.Commands = append(r.current.Commands, Command{
+		Type:     TrianglesCommand,
+		Paint:    *paint,
+		Blend:    recordBlend(op),
+		Scissor:  *scissor,
+		Vertices: verts,
+	})
+}
+
+// Delete implements Renderer.
+func (r *Recorder) Delete() {
+	r.textures = nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	var recorder = NewRecorder()
+	var ctx = CreateContextWithRenderer(recorder, Antialias)
+	if ctx == nil {
+		t.Fatal("CreateContextWithRenderer failed")
+	}
+	defer ctx.Delete()
+	var font = ctx.CreateFont("sans", "nanovg/example/Roboto-Regular.ttf")
+	if font.cFont < 0 {
+		t.Fatal("CreateFont failed")
+	}
+	ctx.BeginFrame(100, 100, 2)
+	ctx.Scissor(10, 10, 40, 20)
+	ctx.BeginPath()
+	ctx.Rect(10, 10, 50, 50)
+	ctx.FillColor(red)
+	ctx.Fill()
+
+	ctx.ResetScissor()
+	ctx.GlobalCompositeOperation(DestinationOut)
+	ctx.BeginPath()
+	ctx.MoveTo(10, 80)
+	ctx.LineTo(90, 80)
+	ctx.StrokeWidth(4)
+	ctx.StrokePaint(ctx.LinearGradientStops(10, 0, 90, 0, GradientStops{{0, red}, {1, blue}}))
+	ctx.Stroke()
+
+	ctx.GlobalCompositeOperation(Lighter)
+	ctx.FontSize(20)
+	ctx.FontFace("sans")
+	ctx.FillColor(black)
+	ctx.Text(10, 50, "Hi")
+	ctx.EndFrame()
+	var frame = recorder.Frame()
+	if frame == nil {
+		t.Fatal("Frame() = nil after EndFrame()")
+	}
+
+	if frame.Width != 100 || frame.Height != 100 || frame.DevicePixelRatio != 2 {
+		t.Errorf("frame size = %vx%v*%v, want 100x100*2", frame.Width, frame.Height, frame.DevicePixelRatio)
+	}
+	var types []CommandType
+	for _, cmd := range frame.Commands {
+		types = append(types, cmd.Type)
+	}
+	if want := []CommandType{FillCommand, StrokeCommand, TrianglesCommand}; !reflect.DeepEqual(types, want) {
+		t.Fatalf("command types = %v, want %v", types, want)
+	}
+
+	var fill = frame.Commands[0]
+	if fill.Paint.InnerColor != [4]float32{1, 0, 0, 1} || fill.Paint.Image != 0 || fill.Paint.Gradient != GradientNone {
+		t.Errorf("fill paint = %+v, want solid red", fill.Paint)
+	}
+	if fill.Blend != (BlendState{One, OneMinusSrcAlpha, One, OneMinusSrcAlpha}) {
+		t.Errorf("fill blend = %+v, want source-over", fill.Blend)
+	}
+	if fill.Scissor.Extent != [2]float32{20, 10} || fill.Scissor.Xform[4] != 30 || fill.Scissor.Xform[5] != 20 {
+		t.Errorf("fill scissor = %+v, want 40x20 at (10,10)", fill.Scissor)
+	}
+	if len(fill.Paths) != 1 || !fill.Paths[0].Closed || !fill.Paths[0].Convex {
+		t.Errorf("fill paths = %+v, want one closed convex path", fill.Paths)
+	}
+
+	var stroke = frame.Commands[1]
+	if stroke.Paint.Gradient != GradientLinear || len(stroke.Paint.Stops) != 2 ||
+		stroke.Paint.Stops[0] != (RenderStop{0, [4]float32{1, 0, 0, 1}}) || stroke.Paint.Stops[1] != (RenderStop{1, [4]float32{0, 0, 1, 1}}) {
+		t.Errorf("stroke paint = %+v, want a linear gradient from red to blue", stroke.Paint)
+	}
+	if stroke.Blend != (BlendState{Zero, OneMinusSrcAlpha, Zero, OneMinusSrcAlpha}) {
+		t.Errorf("stroke blend = %+v, want destination-out", stroke.Blend)
+	}
+	if stroke.Scissor.Extent[0] >= 0 {
+		t.Errorf("stroke scissor = %+v, want disabled", stroke.Scissor)
+	}
+	if stroke.StrokeWidth <= 0 || len(stroke.Paths) != 1 || stroke.Paths[0].Closed {
+		t.Errorf("stroke width %v with paths %+v, want one open path", stroke.StrokeWidth, stroke.Paths)
+	}
+
+	var text = frame.Commands[2]
+	var atlas = recorder.Texture(text.Paint.Image)
+	if atlas == nil || atlas.Type != TextureAlpha {
+		t.Errorf("text paint image %d = %+v, want the font atlas", text.Paint.Image, atlas)
+	}
+	if text.Paint.InnerColor != [4]float32{0, 0, 0, 1} {
+		t.Errorf("text color = %v, want black", text.Paint.InnerColor)
+	}
+	if text.Blend != (BlendState{One, One, One, One}) {
+		t.Errorf("text blend = %+v, want lighter", text.Blend)
+	}
+	// Each glyph is a quad of two triangles.
+	if len(text.Vertices) != 12 {
+		t.Errorf("text has %d vertices, want 12", len(text.Vertices))
+	}
+
+	var buf bytes.Buffer
+	if err := frame.Encode(&buf); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	var decoded, err = DecodeFrame(&buf)
+	if err != nil {
+		t.Fatalf("DecodeFrame failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, frame) {
+		t.Errorf("DecodeFrame(Encode(frame)) differs from frame")
+	}
+}
+
+func TestDecodeFrameUnknownCommand(t *testing.T) {
+	var _, err = DecodeFrame(bytes.NewReader([]byte(`{"Commands": [{"Type": "Clear"}]}`)))
+	if err == nil {
+		t.Error("DecodeFrame of an unknown command type succeeded")
+	}
+}
@@ -0,0 +1,524 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+/*
+#include <stdint.h>
+#include "nanovg/src/nanovg.h"
+
+NVGcontext* nvgoCreateInternal(uintptr_t id, int edgeAntiAlias);
+int nvgoIsInternal(NVGcontext* ctx);
+
+// See c.go.
+typedef struct nvgoGlyph {
+	int font;
+	int index;
+	unsigned int codepoint;
+	float x, y;
+	int advance;
+} nvgoGlyph;
+
+typedef struct nvgoFontInfo {
+	const char* name;
+	const unsigned char* data;
+	int dataSize;
+	float unitsPerEm;
+	int ascent, descent;
+	int bounds[4];
+} nvgoFontInfo;
+
+int nvgoGetFontInfo(NVGcontext* ctx, int fontId, nvgoFontInfo* info);
+*/
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// Renderer is a rendering backend of NanoVGo. NanoVG tessellates the paths
+// and text of a frame into triangles, and hands them over to the renderer
+// together with the paint, composite operation and scissor of each render
+// call.
+//
+// A Renderer can be used to create a context with CreateContextWithRenderer().
+// All methods are called from the goroutine which uses the context.
+type Renderer interface {
+	// CreateTexture creates a texture of typ with the size width*height.
+	// data is nil or holds the pixels of the texture, tightly packed in rows
+	// from top to bottom. Returns a handle to the texture which is greater
+	// than 0, or 0 on failure.
+	CreateTexture(typ TextureType, width, height int, imageFlags ImageFlag, data []byte) int
+	// DeleteTexture deletes the texture image. Returns false if the texture
+	// does not exist.
+	DeleteTexture(image int) bool
+	// UpdateTexture updates the rectangle at (x,y) of size width*height of the
+	// texture image. data holds the pixels of the rectangle only, tightly
+	// packed. Returns false if the texture does not exist.
+	UpdateTexture(image, x, y, width, height int, data []byte) bool
+	// TextureSize returns the size of the texture image, ok is false if the
+	// texture does not exist.
+	TextureSize(image int) (width, height int, ok bool)
+	// Viewport is called by Context.BeginFrame() with the window size and
+	// device pixel ratio of the new frame.
+	Viewport(width, height, devicePixelRatio float32)
+	// Cancel is called by Context.CancelFrame(). Render calls since the last
+	// flush should be discarded.
+	Cancel()
+	// Flush is called by Context.EndFrame(). Render calls since the last
+	// flush should be drawn.
+	Flush()
+	// Fill fills paths with paint. The fill geometry of each path is a
+	// triangle fan, and the stroke geometry is a triangle strip of the
+	// anti-aliasing fringe of fringe width. bounds are the bounds of all paths
+	// as [xmin, ymin, xmax, ymax]. Paths are filled with the non-zero rule
+	// unless there is only one path which is convex.
+	Fill(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath)
+	// Stroke strokes paths of strokeWidth with paint. The stroke geometry of
+	// each path is a triangle strip.
+	Stroke(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, fringe, strokeWidth float32, paths []RenderPath)
+	// Triangles draws a list of textured triangles, which is used for text.
+	// The texture coordinates are in [0,1] of the texture of paint.
+	Triangles(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, verts []Vertex)
+	// Delete is called when the context is deleted.
+	Delete()
+}
+
+// CreateContextWithRenderer creates a NanoVGo context which is rendered by r.
+// flags should be a combination of Antialias, StencilStrokes and Debug, of
+// which only Antialias is used: it decides if NanoVG generates the
+// anti-aliasing fringes of paths. The flags are not passed to r, renderers
+// which support StencilStrokes or Debug take them when they are created, like
+// CreateSoftwareContext() does.
+//
+// Returns nil if the context cannot be created.
+func CreateContextWithRenderer(r Renderer, flags CreateFlag) *Context {
+	renderersMu.Lock()
+	nextRendererID++
+	var id = nextRendererID
+	renderers[id] = &rendererState{
+		r:        r,
+		textures: make(map[int]textureInfo),
+		fonts:    make(map[int]*textFont),
+	}
+	renderersMu.Unlock()
+
+	var edgeAntiAlias C.int
+	if flags&Antialias != 0 {
+		edgeAntiAlias = 1
+	}
+	var ctx = C.nvgoCreateInternal(id, edgeAntiAlias)
+	if ctx == nil {
+		// NanoVG calls renderDelete when it fails after renderCreate, but not
+		// before, so make sure the renderer is released in any case.
+		unregisterRenderer(id)
+		return nil
+	}
+	return (*Context)(ctx)
+}
+
+// TextureType is the pixel format of a texture.
+type TextureType int
+
+// Texture types.
+const (
+	// TextureAlpha has one byte per pixel. It is used for the font atlas.
+	TextureAlpha TextureType = C.NVG_TEXTURE_ALPHA
+	// TextureRGBA has four bytes per pixel in RGBA order.
+	TextureRGBA TextureType = C.NVG_TEXTURE_RGBA
+)
+
+func (typ TextureType) bytesPerPixel() int {
+	if typ == TextureRGBA {
+		return 4
+	}
+	return 1
+}
+
+// Vertex is a vertex of the geometry tessellated by NanoVG. (U,V) are the
+// texture coordinates for text. For paths, U goes across the stroke or fringe
+// from 0 to 1, and V is 1 except at the ends of the butt and square caps, the
+// GL backends use them to compute anti-aliasing.
+type Vertex struct {
+	X, Y, U, V float32
+}
+
+// RenderPath is a path tessellated by NanoVG.
+type RenderPath struct {
+	// Fill is a triangle fan covering the path.
+	Fill []Vertex
+	// Stroke is a triangle strip of the stroke, or the anti-aliasing fringe
+	// when the path is filled.
+	Stroke  []Vertex
+	Closed  bool
+	Winding Winding
+	Convex  bool
+}
+
+// Scissor is the scissor rectangle of a render call. The rectangle is centered
+// at the origin of Xform and extends Extent in each direction. A negative
+// Extent means scissoring is disabled.
+type Scissor struct {
+	Xform  [6]float32
+	Extent [2]float32
+}
+
+// RenderPaint is the paint of a render call, with the transform at the time of
+// the call applied.
+//
+// Gradients are described as a feathered rounded rectangle in the space of
+// Xform: the color is InnerColor inside the rectangle of half size Extent and
+// corner Radius, and fades to OuterColor across Feather. Image patterns have
+// Image set to a texture handle, and cover the rectangle from the origin of
+// Xform to Extent, tinted by InnerColor. The colors are RGBA in [0,1] and not
+// premultiplied.
+//
+// Gradients with color stops have Gradient set, and their color is the color
+// of Stops at the offset t, tinted by InnerColor. Linear gradients run along
+// the x axis of Xform, where t is 0 at x=0 and 1 at x=Extent[0]. Radial
+// gradients are centered at the origin of Xform, where t is 0 at radius Radius
+// and 1 at radius Extent[0]. Conic gradients sweep clockwise around the origin
+// of Xform, where t is the angle from its x axis in turns, in [0,1). The colors of the stops are interpolated
+// premultiplied. Renderers may use a lookup texture of the stops like the GL
+// backends do.
+type RenderPaint struct {
+	Xform      [6]float32
+	Extent     [2]float32
+	Radius     float32
+	Feather    float32
+	InnerColor [4]float32
+	OuterColor [4]float32
+	Image      int
+	Gradient   GradientType `json:",omitempty"`
+	Stops      []RenderStop `json:",omitempty"`
+}
+
+// RenderStop is a color stop of a gradient, sorted by Offset. The color is
+// RGBA in [0,1] and not premultiplied.
+type RenderStop struct {
+	Offset float32
+	Color  [4]float32
+}
+
+// BlendFactors returns the blend factors of state.
+func (state CompositeOperationState) BlendFactors() (srcRGB, dstRGB, srcAlpha, dstAlpha BlendFactor) {
+	var c = state.c()
+	return BlendFactor(c.srcRGB), BlendFactor(c.dstRGB), BlendFactor(c.srcAlpha), BlendFactor(c.dstAlpha)
+}
+
+// textRenderer is implemented by renderers which draw text with the fonts
+// instead of the triangles of the font atlas, such as document backends.
+type textRenderer interface {
+	Renderer
+	// drawText draws a run of glyphs drawn by Context.Text() or
+	// Context.TextBox().
+	drawText(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, run *textRun)
+}
+
+// textRun is a run of glyphs in the space of xform.
+type textRun struct {
+	xform  [6]float32
+	size   float32
+	glyphs []textGlyph
+}
+
+// textGlyph is a glyph positioned at its pen position on the baseline.
+type textGlyph struct {
+	font      *textFont
+	index     int
+	codepoint rune
+	x, y      float32
+	// advance is the advance width in font units.
+	advance int
+}
+
+// textFont is a font created with Context.CreateFont() or
+// Context.CreateFontMem(), with metrics in font units.
+type textFont struct {
+	id              int
+	name            string
+	data            []byte
+	unitsPerEm      float32
+	ascent, descent int
+	bounds          [4]int
+}
+
+type textureInfo struct {
+	typ           TextureType
+	width, height int
+}
+
+type rendererState struct {
+	r        Renderer
+	textures map[int]textureInfo
+	fonts    map[int]*textFont
+}
+
+// font returns the font with id of ctx, with the data copied once per
+// renderer.
+func (state *rendererState) font(ctx *C.NVGcontext, id int) *textFont {
+	if font, ok := state.fonts[id]; ok {
+		return font
+	}
+	var info C.nvgoFontInfo
+	if C.nvgoGetFontInfo(ctx, C.int(id), &info) == 0 {
+		return nil
+	}
+	var font = &textFont{
+		id:         id,
+		name:       C.GoString(info.name),
+		data:       C.GoBytes(unsafe.Pointer(info.data), info.dataSize),
+		unitsPerEm: float32(info.unitsPerEm),
+		ascent:     int(info.ascent),
+		descent:    int(info.descent),
+		bounds:     [4]int{int(info.bounds[0]), int(info.bounds[1]), int(info.bounds[2]), int(info.bounds[3])},
+	}
+	state.fonts[id] = font
+	return font
+}
+
+var (
+	renderersMu    sync.Mutex
+	renderers      = make(map[C.uintptr_t]*rendererState)
+	nextRendererID C.uintptr_t
+)
+
+// deleteInternalContext deletes ctx if it is rendered by a Go renderer, and
+// returns whether it was.
+func deleteInternalContext(ctx *Context) bool {
+	if C.nvgoIsInternal(ctx.c()) == 0 {
+		return false
+	}
+	C.nvgDeleteInternal(ctx.c())
+	return true
+}
+
+func lookupRenderer(id C.uintptr_t) *rendererState {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	return renderers[id]
+}
+
+func unregisterRenderer(id C.uintptr_t) *rendererState {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	var state = renderers[id]
+	delete(renderers, id)
+	return state
+}
+
+func goVertices(verts *C.NVGvertex, n C.int) []Vertex {
+	if n <= 0 {
+		return nil
+	}
+	var goVerts = make([]Vertex, int(n))
+	copy(goVerts, unsafe.Slice((*Vertex)(unsafe.Pointer(verts)), int(n)))
+	return goVerts
+}
+
+func goRenderPaths(paths *C.NVGpath, n C.int) []RenderPath {
+	if n <= 0 {
+		return nil
+	}
+	var goPaths = make([]RenderPath, int(n))
+	for i, path := range unsafe.Slice(paths, int(n)) {
+		goPaths[i] = RenderPath{
+			Fill:    goVertices(path.fill, path.nfill),
+			Stroke:  goVertices(path.stroke, path.nstroke),
+			Closed:  path.closed != 0,
+			Winding: Winding(path.winding),
+			Convex:  path.convex != 0,
+		}
+	}
+	return goPaths
+}
+
+func goScissor(s *C.NVGscissor) *Scissor {
+	var goScissor Scissor
+	for i := range goScissor.Xform {
+		goScissor.Xform[i] = float32(s.xform[i])
+	}
+	goScissor.Extent = [2]float32{float32(s.extent[0]), float32(s.extent[1])}
+	return &goScissor
+}
+
+func goColor(c C.NVGcolor) [4]float32 {
+	return *(*[4]float32)(unsafe.Pointer(&c))
+}
+
+func goRenderPaint(p *C.NVGpaint) *RenderPaint {
+	var paint = RenderPaint{
+		Extent:     [2]float32{float32(p.extent[0]), float32(p.extent[1])},
+		Radius:     float32(p.radius),
+		Feather:    float32(p.feather),
+		InnerColor: goColor(p.innerColor),
+		OuterColor: goColor(p.outerColor),
+		Image:      int(p.image),
+		Gradient:   GradientType(p.gradient),
+	}
+	for i := range paint.Xform {
+		paint.Xform[i] = float32(p.xform[i])
+	}
+	if paint.Gradient != GradientNone {
+		paint.Stops = make([]RenderStop, p.nstops)
+		for i := range paint.Stops {
+			paint.Stops[i] = RenderStop{float32(p.stops[i].offset), goColor(p.stops[i].color)}
+		}
+	}
+	return &paint
+}
+
+//export nvgoRenderCreate
+func nvgoRenderCreate(uptr C.uintptr_t) C.int {
+	if lookupRenderer(uptr) == nil {
+		return 0
+	}
+	return 1
+}
+
+//export nvgoRenderCreateTexture
+func nvgoRenderCreateTexture(uptr C.uintptr_t, typ, w, h, imageFlags C.int, data *C.uchar) C.int {
+	var state = lookupRenderer(uptr)
+	var info = textureInfo{typ: TextureType(typ), width: int(w), height: int(h)}
+	var goData []byte
+	if data != nil {
+		goData = C.GoBytes(unsafe.Pointer(data), C.int(info.width*info.height*info.typ.bytesPerPixel()))
+	}
+	var image = state.r.CreateTexture(info.typ, info.width, info.height, ImageFlag(imageFlags), goData)
+	if image != 0 {
+		state.textures[image] = info
+	}
+	return C.int(image)
+}
+
+//export nvgoRenderDrawsText
+func nvgoRenderDrawsText(uptr C.uintptr_t) C.int {
+	if _, ok := lookupRenderer(uptr).r.(textRenderer); !ok {
+		return 0
+	}
+	return 1
+}
+
+//export nvgoRenderText
+func nvgoRenderText(uptr C.uintptr_t, ctx *C.NVGcontext, paint *C.NVGpaint, op C.NVGcompositeOperationState, s *C.NVGscissor, fontSize C.float, xform *C.float, glyphs *C.nvgoGlyph, nglyphs C.int) {
+	var state = lookupRenderer(uptr)
+	var run = textRun{size: float32(fontSize)}
+	for i, f := range unsafe.Slice(xform, 6) {
+		run.xform[i] = float32(f)
+	}
+	if nglyphs > 0 {
+		for _, glyph := range unsafe.Slice(glyphs, int(nglyphs)) {
+			var font = state.font(ctx, int(glyph.font))
+			if font == nil {
+				continue
+			}
+			run.glyphs = append(run.glyphs, textGlyph{
+				font:      font,
+				index:     int(glyph.index),
+				codepoint: rune(glyph.codepoint),
+				x:         float32(glyph.x),
+				y:         float32(glyph.y),
+				advance:   int(glyph.advance),
+			})
+		}
+	}
+	state.r.(textRenderer).drawText(goRenderPaint(paint), CompositeOperationState(op), goScissor(s), &run)
+}
+
+//export nvgoRenderDeleteTexture
+func nvgoRenderDeleteTexture(uptr C.uintptr_t, image C.int) C.int {
+	var state = lookupRenderer(uptr)
+	delete(state.textures, int(image))
+	if !state.r.DeleteTexture(int(image)) {
+		return 0
+	}
+	return 1
+}
+
+//export nvgoRenderUpdateTexture
+func nvgoRenderUpdateTexture(uptr C.uintptr_t, image, x, y, w, h C.int, data *C.uchar) C.int {
+	var state = lookupRenderer(uptr)
+	var info, ok = state.textures[int(image)]
+	if !ok || data == nil {
+		return 0
+	}
+
+	// NanoVG passes the data of the whole texture, only the updated rectangle
+	// is handed to the renderer.
+	var bpp = info.typ.bytesPerPixel()
+	var src = unsafe.Slice((*byte)(unsafe.Pointer(data)), info.width*info.height*bpp)
+	var rowLen = int(w) * bpp
+	var rect = make([]byte, 0, rowLen*int(h))
+	for row := int(y); row < int(y+h); row++ {
+		var start = (row*info.width + int(x)) * bpp
+		rect = append(rect, src[start:start+rowLen]...)
+	}
+	if !state.r.UpdateTexture(int(image), int(x), int(y), int(w), int(h), rect) {
+		return 0
+	}
+	return 1
+}
+
+//export nvgoRenderGetTextureSize
+func nvgoRenderGetTextureSize(uptr C.uintptr_t, image C.int, w, h *C.int) C.int {
+	var width, height, ok = lookupRenderer(uptr).r.TextureSize(int(image))
+	if !ok {
+		return 0
+	}
+	*w, *h = C.int(width), C.int(height)
+	return 1
+}
+
+//export nvgoRenderViewport
+func nvgoRenderViewport(uptr C.uintptr_t, width, height, devicePixelRatio C.float) {
+	lookupRenderer(uptr).r.Viewport(float32(width), float32(height), float32(devicePixelRatio))
+}
+
+//export nvgoRenderCancel
+func nvgoRenderCancel(uptr C.uintptr_t) {
+	lookupRenderer(uptr).r.Cancel()
+}
+
+//export nvgoRenderFlush
+func nvgoRenderFlush(uptr C.uintptr_t) {
+	lookupRenderer(uptr).r.Flush()
+}
+
+//export nvgoRenderFill
+func nvgoRenderFill(uptr C.uintptr_t, paint *C.NVGpaint, op C.NVGcompositeOperationState, s *C.NVGscissor, fringe C.float, bounds *C.float, paths *C.NVGpath, npaths C.int) {
+	var cBounds = unsafe.Slice(bounds, 4)
+	var goBounds = [4]float32{float32(cBounds[0]), float32(cBounds[1]), float32(cBounds[2]), float32(cBounds[3])}
+	lookupRenderer(uptr).r.Fill(goRenderPaint(paint), CompositeOperationState(op), goScissor(s), float32(fringe), goBounds, goRenderPaths(paths, npaths))
+}
+
+//export nvgoRenderStroke
+func nvgoRenderStroke(uptr C.uintptr_t, paint *C.NVGpaint, op C.NVGcompositeOperationState, s *C.NVGscissor, fringe, strokeWidth C.float, paths *C.NVGpath, npaths C.int) {
+	lookupRenderer(uptr).r.Stroke(goRenderPaint(paint), CompositeOperationState(op), goScissor(s), float32(fringe), float32(strokeWidth), goRenderPaths(paths, npaths))
+}
+
+//export nvgoRenderTriangles
+func nvgoRenderTriangles(uptr C.uintptr_t, paint *C.NVGpaint, op C.NVGcompositeOperationState, s *C.NVGscissor, verts *C.NVGvertex, nverts C.int) {
+	lookupRenderer(uptr).r.Triangles(goRenderPaint(paint), CompositeOperationState(op), goScissor(s), goVertices(verts, nverts))
+}
+
+//export nvgoRenderDelete
+func nvgoRenderDelete(uptr C.uintptr_t) {
+	if state := unregisterRenderer(uptr); state != nil {
+		state.r.Delete()
+	}
+}
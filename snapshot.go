@@ -0,0 +1,102 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+/*
+#include "nanovgo_gl.h"
+#include "nanovg/src/nanovg.h"
+
+int nvgoIsInternal(NVGcontext* ctx);
+*/
+import "C"
+import (
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"unsafe"
+)
+
+// Snapshot reads the rectangle at (x,y) of size width*height of the bound
+// framebuffer, which is the window or a bound Framebuffer. (x,y) is relative to
+// the top left corner of the OpenGL viewport. It should be called after
+// Context.EndFrame().
+//
+// premultiplied tells if the framebuffer holds colors premultiplied by alpha,
+// with alpha being meaningful, i.e. when frames are drawn over a transparent
+// clear color. The pixels are then returned as they are, as image.RGBA holds
+// premultiplied colors, and are un-premultiplied when the image is encoded,
+// e.g. by EncodePNG(). Otherwise the framebuffer is treated as opaque, and
+// alpha is set to 255.
+func (ctx *Context) Snapshot(x, y, width, height int, premultiplied bool) (*image.RGBA, error) {
+	if C.nvgoIsInternal(ctx.c()) != 0 {
+		return nil, errors.New("nanovgo: snapshots need a context rendered by OpenGL")
+	}
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("nanovgo: invalid snapshot size")
+	}
+	var img = image.NewRGBA(image.Rect(0, 0, width, height))
+	if !readPixels(x, y, width, height, img.Pix) {
+		return nil, errors.New("nanovgo: cannot read the framebuffer")
+	}
+	flipRows(img)
+	if !premultiplied {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 255
+		}
+	}
+	return img, nil
+}
+
+// EncodePNG writes a snapshot of the bound framebuffer to w as PNG, see
+// Context.Snapshot().
+func (ctx *Context) EncodePNG(w io.Writer, x, y, width, height int, premultiplied bool) error {
+	var img, err = ctx.Snapshot(x, y, width, height, premultiplied)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// flipRows flips an image read from OpenGL, whose rows go from bottom to top.
+func flipRows(img *image.RGBA) {
+	var height = img.Rect.Dy()
+	var row = make([]byte, img.Stride)
+	for y := 0; y < height/2; y++ {
+		var top = img.Pix[y*img.Stride : (y+1)*img.Stride]
+		var bottom = img.Pix[(height-1-y)*img.Stride : (height-y)*img.Stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+}
+
+// readPixels reads the RGBA pixels of the rectangle at (x,y) of size
+// width*height of the viewport of the bound framebuffer, in rows from bottom to
+// top as in OpenGL. Returns false on an OpenGL error.
+func readPixels(x, y, width, height int, pix []byte) bool {
+	for C.glGetError() != C.GL_NO_ERROR {
+	}
+	var viewport [4]C.GLint
+	C.glGetIntegerv(C.GL_VIEWPORT, &viewport[0])
+	C.glPixelStorei(C.GL_PACK_ALIGNMENT, 1)
+	C.glReadPixels(viewport[0]+C.GLint(x), viewport[1]+viewport[3]-C.GLint(y+height), C.GLsizei(width), C.GLsizei(height),
+		C.GL_RGBA, C.GL_UNSIGNED_BYTE, unsafe.Pointer(&pix[0]))
+	return C.glGetError() == C.GL_NO_ERROR
+}
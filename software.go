@@ -0,0 +1,828 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// CreateSoftwareContext creates a NanoVGo context which renders into img on
+// the CPU, without the need of an OpenGL context. flags should be a
+// combination of Antialias, StencilStrokes and Debug.
+//
+// The software renderer follows the OpenGL 3 backend closely, so the output is
+// close to what is rendered on screen. The drawing commands of a frame are
+// rasterized into img when Context.EndFrame() is called. The window size
+// passed to Context.BeginFrame() is stretched to the bounds of img, so the
+// device pixel ratio should be set to the ratio between the two for crisp
+// output. Like the framebuffer of the GL backends, img is not cleared between
+// frames.
+//
+// Images are sampled from their base level only, ImageGenerateMipmaps has no
+// effect.
+func CreateSoftwareContext(img *image.RGBA, flags CreateFlag) *Context {
+	return CreateContextWithRenderer(newSoftwareRenderer(img, flags), flags)
+}
+
+// Shader types, see NSVG_SHADER_* in nanovg_gl.h.
+const (
+	shaderFillGradient = iota
+	shaderFillImage
+	shaderSimple
+	shaderImage
+	shaderFillLinear
+	shaderFillRadial
+	shaderFillConic
+)
+
+// Render call types.
+const (
+	callFill = iota
+	callConvexFill
+	callStroke
+	callTriangles
+)
+
+type softwareTexture struct {
+	typ           TextureType
+	width, height int
+	flags         ImageFlag
+	data          []byte
+}
+
+// softwareUniforms are the uniforms of the fragment shader of the GL backends.
+type softwareUniforms struct {
+	typ          int
+	texType      int
+	scissorMat   [6]float32
+	paintMat     [6]float32
+	innerColor   [4]float32
+	outerColor   [4]float32
+	scissorExt   [2]float32
+	scissorScale [2]float32
+	extent       [2]float32
+	radius       float32
+	feather      float32
+	strokeMult   float32
+	strokeThr    float32
+}
+
+type softwareCall struct {
+	typ       int
+	image     int
+	blend     [4]BlendFactor
+	paths     []RenderPath
+	triangles []Vertex
+	uniforms  []softwareUniforms
+}
+
+type softwareRenderer struct {
+	img         *image.RGBA
+	flags       CreateFlag
+	view        [2]float32
+	textures    map[int]*softwareTexture
+	nextTexture int
+	ramps       map[string]*softwareRamp
+	calls       []softwareCall
+	stencil     []uint8
+}
+
+// softwareRamp is the lookup texture of the color stops of a gradient, see
+// GLNVGramp in nanovg_gl.h.
+type softwareRamp struct {
+	image int
+	used  bool
+}
+
+func newSoftwareRenderer(img *image.RGBA, flags CreateFlag) *softwareRenderer {
+	return &softwareRenderer{
+		img:      img,
+		flags:    flags,
+		textures: make(map[int]*softwareTexture),
+		ramps:    make(map[string]*softwareRamp),
+	}
+}
+
+func (r *softwareRenderer) CreateTexture(typ TextureType, width, height int, imageFlags ImageFlag, data []byte) int {
+	var tex = &softwareTexture{
+		typ:    typ,
+		width:  width,
+		height: height,
+		flags:  imageFlags,
+		data:   make([]byte, width*height*typ.bytesPerPixel()),
+	}
+	copy(tex.data, data)
+	r.nextTexture++
+	r.textures[r.nextTexture] = tex
+	return r.nextTexture
+}
+
+func (r *softwareRenderer) DeleteTexture(image int) bool {
+	var tex = r.textures[image]
+	if tex == nil {
+		return false
+	}
+	delete(r.textures, image)
+	return true
+}
+
+func (r *softwareRenderer) UpdateTexture(image, x, y, width, height int, data []byte) bool {
+	var tex = r.textures[image]
+	if tex == nil {
+		return false
+	}
+	var bpp = tex.typ.bytesPerPixel()
+	var rowLen = width * bpp
+	for row := 0; row < height; row++ {
+		var start = ((y+row)*tex.width + x) * bpp
+		copy(tex.data[start:start+rowLen], data[row*rowLen:])
+	}
+	return true
+}
+
+func (r *softwareRenderer) TextureSize(image int) (width, height int, ok bool) {
+	var tex = r.textures[image]
+	if tex == nil {
+		return 0, 0, false
+	}
+	return tex.width, tex.height, true
+}
+
+func (r *softwareRenderer) Viewport(width, height, devicePixelRatio float32) {
+	r.view = [2]float32{width, height}
+}
+
+func (r *softwareRenderer) Cancel() {
+	r.calls = r.calls[:0]
+}
+
+func (r *softwareRenderer) Delete() {
+	r.textures = nil
+	r.ramps = nil
+	r.calls = nil
+}
+
+// paintImage returns the texture paint is drawn with, which is a lookup
+// texture for gradients with color stops. Lookup textures are created on
+// first use and deleted after a frame they are not used in.
+func (r *softwareRenderer) paintImage(paint *RenderPaint) int {
+	if paint.Gradient == GradientNone {
+		return paint.Image
+	}
+	var key = fmt.Sprint(paint.Stops)
+	var ramp = r.ramps[key]
+	if ramp == nil {
+		ramp = &softwareRamp{image: r.CreateTexture(TextureRGBA, rampSize, 1, ImagePremultiplied, gradientRamp(paint))}
+		r.ramps[key] = ramp
+	}
+	ramp.used = true
+	return ramp.image
+}
+
+// releaseRamps deletes the lookup textures not used since the last call.
+func (r *softwareRenderer) releaseRamps() {
+	for key, ramp := range r.ramps {
+		if !ramp.used {
+			r.DeleteTexture(ramp.image)
+			delete(r.ramps, key)
+		}
+		ramp.used = false
+	}
+}
+
+func (r *softwareRenderer) Fill(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, fringe float32, bounds [4]float32, paths []RenderPath) {
+	var call = softwareCall{
+		typ:   callFill,
+		image: r.paintImage(paint),
+		blend: blendFunc(op),
+		paths: paths,
+	}
+	if len(paths) == 1 && paths[0].Convex {
+		call.typ = callConvexFill
+		call.uniforms = []softwareUniforms{r.convertPaint(paint, scissor, fringe, fringe, -1)}
+	} else {
+		// The bounding box is covered after the stencil is set up.
+		call.triangles = []Vertex{
+			{bounds[2], bounds[3], 0.5, 1},
+			{bounds[2], bounds[1], 0.5, 1},
+			{bounds[0], bounds[3], 0.5, 1},
+			{bounds[0], bounds[1], 0.5, 1},
+		}
+		call.uniforms = []softwareUniforms{
+			{typ: shaderSimple, strokeThr: -1},
+			r.convertPaint(paint, scissor, fringe, fringe, -1),
+		}
+	}
+	r.calls = append(r.calls, call)
+}
+
+func (r *softwareRenderer) Stroke(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, fringe, strokeWidth float32, paths []RenderPath) {
+	var call = softwareCall{
+		typ:      callStroke,
+		image:    r.paintImage(paint),
+		blend:    blendFunc(op),
+		paths:    paths,
+		uniforms: []softwareUniforms{r.convertPaint(paint, scissor, strokeWidth, fringe, -1)},
+	}
+	if r.flags&StencilStrokes != 0 {
+		call.uniforms = append(call.uniforms, r.convertPaint(paint, scissor, strokeWidth, fringe, 1-0.5/255))
+	}
+	r.calls = append(r.calls, call)
+}
+
+func (r *softwareRenderer) Triangles(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, verts []Vertex) {
+	var uniforms = r.convertPaint(paint, scissor, 1, 1, -1)
+	uniforms.typ = shaderImage
+	r.calls = append(r.calls, softwareCall{
+		typ:       callTriangles,
+		image:     paint.Image,
+		blend:     blendFunc(op),
+		triangles: verts,
+		uniforms:  []softwareUniforms{uniforms},
+	})
+}
+
+// blendFunc returns the blend factors of op in the order of
+// glBlendFuncSeparate. Invalid factors fall back to SourceOver.
+func blendFunc(op CompositeOperationState) [4]BlendFactor {
+	var srcRGB, dstRGB, srcAlpha, dstAlpha = op.BlendFactors()
+	var factors = [4]BlendFactor{srcRGB, dstRGB, srcAlpha, dstAlpha}
+	for _, factor := range factors {
+		if factor < Zero || factor > SrcAlphaSaturate || factor&(factor-1) != 0 {
+			return [4]BlendFactor{One, OneMinusSrcAlpha, One, OneMinusSrcAlpha}
+		}
+	}
+	return factors
+}
+
+func premulColor(c [4]float32) [4]float32 {
+	return [4]float32{c[0] * c[3], c[1] * c[3], c[2] * c[3], c[3]}
+}
+
+func inverseXform(xform [6]float32) [6]float32 {
+	var inv [6]float32
+	TransformInverse(&inv, xform)
+	return inv
+}
+
+func (r *softwareRenderer) convertPaint(paint *RenderPaint, scissor *Scissor, width, fringe, strokeThr float32) softwareUniforms {
+	var frag = softwareUniforms{
+		innerColor: premulColor(paint.InnerColor),
+		outerColor: premulColor(paint.OuterColor),
+		extent:     paint.Extent,
+		strokeMult: (width*0.5 + fringe*0.5) / fringe,
+		strokeThr:  strokeThr,
+	}
+
+	if scissor.Extent[0] < -0.5 || scissor.Extent[1] < -0.5 {
+		frag.scissorExt = [2]float32{1, 1}
+		frag.scissorScale = [2]float32{1, 1}
+	} else {
+		var xf = scissor.Xform
+		frag.scissorMat = inverseXform(xf)
+		frag.scissorExt = scissor.Extent
+		frag.scissorScale[0] = float32(math.Sqrt(float64(xf[0]*xf[0]+xf[2]*xf[2]))) / fringe
+		frag.scissorScale[1] = float32(math.Sqrt(float64(xf[1]*xf[1]+xf[3]*xf[3]))) / fringe
+	}
+
+	if paint.Gradient != GradientNone {
+		switch paint.Gradient {
+		case GradientLinear:
+			frag.typ = shaderFillLinear
+		case GradientRadial:
+			frag.typ = shaderFillRadial
+		default:
+			frag.typ = shaderFillConic
+		}
+		frag.radius = paint.Radius
+		frag.feather = paint.Extent[0] - paint.Radius
+		frag.paintMat = inverseXform(paint.Xform)
+	} else if tex := r.textures[paint.Image]; paint.Image != 0 && tex != nil {
+		var xform = paint.Xform
+		if tex.flags&ImageFlipy != 0 {
+			var m1, m2 [6]float32
+			TransformTranslate(&m1, 0, frag.extent[1]*0.5)
+			TransformMultiply(&m1, paint.Xform)
+			TransformScale(&m2, 1, -1)
+			TransformMultiply(&m2, m1)
+			TransformTranslate(&m1, 0, -frag.extent[1]*0.5)
+			TransformMultiply(&m1, m2)
+			xform = m1
+		}
+		frag.typ = shaderFillImage
+		frag.paintMat = inverseXform(xform)
+		switch {
+		case tex.typ != TextureRGBA:
+			frag.texType = 2
+		case tex.flags&ImagePremultiplied != 0:
+			frag.texType = 0
+		default:
+			frag.texType = 1
+		}
+	} else {
+		frag.typ = shaderFillGradient
+		frag.radius = paint.Radius
+		frag.feather = paint.Feather
+		frag.paintMat = inverseXform(paint.Xform)
+	}
+	return frag
+}
+
+func (r *softwareRenderer) Flush() {
+	var bounds = r.img.Bounds()
+	if r.view[0] > 0 && r.view[1] > 0 && !bounds.Empty() {
+		if n := bounds.Dx() * bounds.Dy(); len(r.stencil) != n {
+			r.stencil = make([]uint8, n)
+		}
+		var ras = softwareRasterizer{
+			r:         r,
+			width:     bounds.Dx(),
+			height:    bounds.Dy(),
+			scaleX:    float64(bounds.Dx()) / float64(r.view[0]),
+			scaleY:    float64(bounds.Dy()) / float64(r.view[1]),
+			antialias: r.flags&Antialias != 0,
+		}
+		for i := range r.calls {
+			ras.draw(&r.calls[i])
+		}
+	}
+	r.calls = r.calls[:0]
+	r.releaseRamps()
+}
+
+// softwareRasterizer executes render calls the way the GL backends do: paths
+// are tessellated into triangles by NanoVG, non-convex fills use the stencil
+// buffer, and anti-aliasing is computed by the fragment shader from the
+// texture coordinates of the fringe geometry.
+type softwareRasterizer struct {
+	r              *softwareRenderer
+	width, height  int
+	scaleX, scaleY float64
+	antialias      bool
+
+	// State of the current call.
+	frag  *softwareUniforms
+	tex   *softwareTexture
+	blend [4]BlendFactor
+}
+
+// fragmentFunc is called for each pixel (x,y) covered by a triangle, front
+// tells if the triangle is front facing, and (u,v) are the interpolated
+// texture coordinates.
+type fragmentFunc func(x, y int, front bool, u, v float32)
+
+func (ras *softwareRasterizer) draw(call *softwareCall) {
+	ras.tex = ras.r.textures[call.image]
+	ras.blend = call.blend
+	switch call.typ {
+	case callFill:
+		ras.fill(call)
+	case callConvexFill:
+		ras.convexFill(call)
+	case callStroke:
+		ras.stroke(call)
+	case callTriangles:
+		ras.frag = &call.uniforms[0]
+		ras.drawTriangles(call.triangles, true, ras.shadeFragment)
+	}
+}
+
+func (ras *softwareRasterizer) fill(call *softwareCall) {
+	var stencil = ras.r.stencil
+
+	// Set up the stencil buffer for the non-zero fill rule.
+	ras.frag = &call.uniforms[0]
+	for _, path := range call.paths {
+		ras.drawFan(path.Fill, false, func(x, y int, front bool, u, v float32) {
+			if front {
+				stencil[y*ras.width+x]++
+			} else {
+				stencil[y*ras.width+x]--
+			}
+		})
+	}
+
+	// Draw anti-aliased pixels outside of the shape.
+	ras.frag = &call.uniforms[1]
+	if ras.antialias {
+		for _, path := range call.paths {
+			ras.drawStrip(path.Stroke, true, func(x, y int, front bool, u, v float32) {
+				if stencil[y*ras.width+x] == 0 {
+					ras.shade(x, y, u, v)
+				}
+			})
+		}
+	}
+
+	// Draw the fill and clear the stencil buffer.
+	ras.drawStrip(call.triangles, true, func(x, y int, front bool, u, v float32) {
+		var i = y*ras.width + x
+		if stencil[i] != 0 {
+			ras.shade(x, y, u, v)
+		}
+		stencil[i] = 0
+	})
+}
+
+func (ras *softwareRasterizer) convexFill(call *softwareCall) {
+	ras.frag = &call.uniforms[0]
+	for _, path := range call.paths {
+		ras.drawFan(path.Fill, true, ras.shadeFragment)
+	}
+	if ras.antialias {
+		for _, path := range call.paths {
+			ras.drawStrip(path.Stroke, true, ras.shadeFragment)
+		}
+	}
+}
+
+func (ras *softwareRasterizer) stroke(call *softwareCall) {
+	if len(call.uniforms) == 1 {
+		ras.frag = &call.uniforms[0]
+		for _, path := range call.paths {
+			ras.drawStrip(path.Stroke, true, ras.shadeFragment)
+		}
+		return
+	}
+
+	var stencil = ras.r.stencil
+
+	// Fill the stroke base without overlap.
+	ras.frag = &call.uniforms[1]
+	for _, path := range call.paths {
+		ras.drawStrip(path.Stroke, true, func(x, y int, front bool, u, v float32) {
+			var i = y*ras.width + x
+			if stencil[i] == 0 && ras.shade(x, y, u, v) && stencil[i] < 0xff {
+				stencil[i]++
+			}
+		})
+	}
+
+	// Draw anti-aliased pixels.
+	ras.frag = &call.uniforms[0]
+	for _, path := range call.paths {
+		ras.drawStrip(path.Stroke, true, func(x, y int, front bool, u, v float32) {
+			if stencil[y*ras.width+x] == 0 {
+				ras.shade(x, y, u, v)
+			}
+		})
+	}
+
+	// Clear the stencil buffer.
+	for _, path := range call.paths {
+		ras.drawStrip(path.Stroke, true, func(x, y int, front bool, u, v float32) {
+			stencil[y*ras.width+x] = 0
+		})
+	}
+}
+
+func (ras *softwareRasterizer) drawFan(verts []Vertex, cull bool, fn fragmentFunc) {
+	for i := 2; i < len(verts); i++ {
+		ras.drawTriangle(verts[0], verts[i-1], verts[i], cull, fn)
+	}
+}
+
+func (ras *softwareRasterizer) drawStrip(verts []Vertex, cull bool, fn fragmentFunc) {
+	for i := 2; i < len(verts); i++ {
+		// Every other triangle of a strip has its order reversed to keep the
+		// winding consistent.
+		if i%2 == 0 {
+			ras.drawTriangle(verts[i-2], verts[i-1], verts[i], cull, fn)
+		} else {
+			ras.drawTriangle(verts[i-1], verts[i-2], verts[i], cull, fn)
+		}
+	}
+}
+
+func (ras *softwareRasterizer) drawTriangles(verts []Vertex, cull bool, fn fragmentFunc) {
+	for i := 2; i < len(verts); i += 3 {
+		ras.drawTriangle(verts[i-2], verts[i-1], verts[i], cull, fn)
+	}
+}
+
+// edgeFunction is the edge function a*x + b*y + c of a triangle edge, which is
+// positive inside the triangle.
+type edgeFunction struct {
+	a, b, c float64
+	topLeft bool
+}
+
+// newEdgeFunction returns the edge function of the edge from (x0,y0) to
+// (x1,y1). The coefficients are computed with the end points in a canonical
+// order, so that triangles sharing an edge get exactly opposite values, which
+// makes the rasterization watertight.
+func newEdgeFunction(x0, y0, x1, y1 float64) edgeFunction {
+	var sign = 1.0
+	if x0 > x1 || (x0 == x1 && y0 > y1) {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+		sign = -1
+	}
+	var dx, dy = x1 - x0, y1 - y0
+	return edgeFunction{
+		a: -dy,
+		b: dx,
+		c: dy*x0 - dx*y0,
+		// With y pointing down, the interior is below top edges and to the
+		// right of left edges.
+		topLeft: (dy == 0 && sign*dx > 0) || sign*dy < 0,
+	}.scale(sign)
+}
+
+func (e edgeFunction) scale(s float64) edgeFunction {
+	e.a, e.b, e.c = e.a*s, e.b*s, e.c*s
+	return e
+}
+
+func (e edgeFunction) eval(x, y float64) float64 {
+	return e.a*x + e.b*y + e.c
+}
+
+func (e edgeFunction) covers(w float64) bool {
+	return w > 0 || (w == 0 && e.topLeft)
+}
+
+// drawTriangle calls fn for the pixels whose centers are covered by the
+// triangle (v0,v1,v2), following the top-left rule of OpenGL. Back facing
+// triangles are skipped if cull is true.
+func (ras *softwareRasterizer) drawTriangle(v0, v1, v2 Vertex, cull bool, fn fragmentFunc) {
+	var x0, y0 = float64(v0.X) * ras.scaleX, float64(v0.Y) * ras.scaleY
+	var x1, y1 = float64(v1.X) * ras.scaleX, float64(v1.Y) * ras.scaleY
+	var x2, y2 = float64(v2.X) * ras.scaleX, float64(v2.Y) * ras.scaleY
+
+	// Triangles are counter-clockwise on screen when front facing, which is a
+	// negative area with y pointing down.
+	var area = (x1-x0)*(y2-y0) - (y1-y0)*(x2-x0)
+	if area == 0 || math.IsNaN(area) {
+		return
+	}
+	var front = area < 0
+	if cull && !front {
+		return
+	}
+	if front {
+		v1, v2 = v2, v1
+		x1, y1, x2, y2 = x2, y2, x1, y1
+		area = -area
+	}
+
+	var e0 = newEdgeFunction(x1, y1, x2, y2)
+	var e1 = newEdgeFunction(x2, y2, x0, y0)
+	var e2 = newEdgeFunction(x0, y0, x1, y1)
+
+	var minX = math.Max(math.Ceil(math.Min(x0, math.Min(x1, x2))-0.5), 0)
+	var maxX = math.Min(math.Floor(math.Max(x0, math.Max(x1, x2))-0.5), float64(ras.width-1))
+	var minY = math.Max(math.Ceil(math.Min(y0, math.Min(y1, y2))-0.5), 0)
+	var maxY = math.Min(math.Floor(math.Max(y0, math.Max(y1, y2))-0.5), float64(ras.height-1))
+
+	for y := int(minY); float64(y) <= maxY; y++ {
+		var py = float64(y) + 0.5
+		for x := int(minX); float64(x) <= maxX; x++ {
+			var px = float64(x) + 0.5
+			var w0, w1, w2 = e0.eval(px, py), e1.eval(px, py), e2.eval(px, py)
+			if !e0.covers(w0) || !e1.covers(w1) || !e2.covers(w2) {
+				continue
+			}
+			var l0, l1, l2 = float32(w0 / area), float32(w1 / area), float32(w2 / area)
+			fn(x, y, front, l0*v0.U+l1*v1.U+l2*v2.U, l0*v0.V+l1*v1.V+l2*v2.V)
+		}
+	}
+}
+
+// shadeFragment is a fragmentFunc which shades all fragments.
+func (ras *softwareRasterizer) shadeFragment(x, y int, front bool, u, v float32) {
+	ras.shade(x, y, u, v)
+}
+
+// shade runs the fragment shader for pixel (x,y) and blends the result into
+// the image. It returns false if the fragment is discarded.
+func (ras *softwareRasterizer) shade(x, y int, u, v float32) bool {
+	var frag = ras.frag
+	var fx = float32((float64(x) + 0.5) / ras.scaleX)
+	var fy = float32((float64(y) + 0.5) / ras.scaleY)
+
+	var scissor = frag.scissorMask(fx, fy)
+	var strokeAlpha float32 = 1
+	if ras.antialias {
+		strokeAlpha = minf(1, (1-absf(u*2-1))*frag.strokeMult) * minf(1, v)
+		if strokeAlpha < frag.strokeThr {
+			return false
+		}
+	}
+
+	var color [4]float32
+	switch frag.typ {
+	case shaderFillGradient:
+		var px, py = transformPoint(frag.paintMat, fx, fy)
+		var d = (sdRoundRect(px, py, frag.extent, frag.radius) + frag.feather*0.5) / frag.feather
+		d = clampf(d, 0, 1)
+		for i := range color {
+			color[i] = (frag.innerColor[i]*(1-d) + frag.outerColor[i]*d) * strokeAlpha * scissor
+		}
+	case shaderFillImage:
+		var px, py = transformPoint(frag.paintMat, fx, fy)
+		color = ras.sample(px/frag.extent[0], py/frag.extent[1])
+		for i := range color {
+			color[i] *= frag.innerColor[i] * strokeAlpha * scissor
+		}
+	case shaderSimple:
+		color = [4]float32{1, 1, 1, 1}
+	case shaderImage:
+		color = ras.sample(u, v)
+		for i := range color {
+			color[i] *= scissor * frag.innerColor[i]
+		}
+	case shaderFillLinear, shaderFillRadial, shaderFillConic:
+		var px, py = transformPoint(frag.paintMat, fx, fy)
+		var t = px
+		switch frag.typ {
+		case shaderFillRadial:
+			t = float32(math.Sqrt(float64(px*px + py*py)))
+		case shaderFillConic:
+			var turns = math.Atan2(float64(py), float64(px)) / (2 * math.Pi)
+			t = float32(turns - math.Floor(turns))
+		}
+		t = clampf((t-frag.radius)/frag.feather, 0, 1)
+		color = ras.sample((t*(rampSize-1)+0.5)/rampSize, 0.5)
+		for i := range color {
+			color[i] *= frag.innerColor[i] * strokeAlpha * scissor
+		}
+	}
+
+	ras.blendPixel(x, y, color)
+	return true
+}
+
+// sample samples the texture of the current call at (s,t), and converts the
+// texel to a premultiplied color according to the texture type.
+func (ras *softwareRasterizer) sample(s, t float32) [4]float32 {
+	var color [4]float32
+	var tex = ras.tex
+	if tex == nil || tex.width == 0 || tex.height == 0 {
+		return color
+	}
+
+	if tex.flags&ImageNearest != 0 {
+		color = tex.texel(int(math.Floor(float64(s)*float64(tex.width))), int(math.Floor(float64(t)*float64(tex.height))))
+	} else {
+		var x = float64(s)*float64(tex.width) - 0.5
+		var y = float64(t)*float64(tex.height) - 0.5
+		var x0, y0 = math.Floor(x), math.Floor(y)
+		var fx, fy = float32(x - x0), float32(y - y0)
+		var ix, iy = int(x0), int(y0)
+		var c00, c10 = tex.texel(ix, iy), tex.texel(ix+1, iy)
+		var c01, c11 = tex.texel(ix, iy+1), tex.texel(ix+1, iy+1)
+		for i := range color {
+			color[i] = (c00[i]*(1-fx)+c10[i]*fx)*(1-fy) + (c01[i]*(1-fx)+c11[i]*fx)*fy
+		}
+	}
+
+	switch ras.frag.texType {
+	case 1:
+		color = premulColor(color)
+	case 2:
+		color = [4]float32{color[0], color[0], color[0], color[0]}
+	}
+	return color
+}
+
+// texel returns the texel at (x,y) with the wrap mode of tex applied. Alpha
+// textures return the value in the red component like GL_RED textures do.
+func (tex *softwareTexture) texel(x, y int) [4]float32 {
+	x = wrapTexel(x, tex.width, tex.flags&ImageRepeatX != 0)
+	y = wrapTexel(y, tex.height, tex.flags&ImageRepeatY != 0)
+	if tex.typ == TextureRGBA {
+		var p = tex.data[(y*tex.width+x)*4:]
+		return [4]float32{float32(p[0]) / 255, float32(p[1]) / 255, float32(p[2]) / 255, float32(p[3]) / 255}
+	}
+	return [4]float32{float32(tex.data[y*tex.width+x]) / 255, 0, 0, 1}
+}
+
+func wrapTexel(i, n int, repeat bool) int {
+	if repeat {
+		i %= n
+		if i < 0 {
+			i += n
+		}
+		return i
+	}
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+// blendPixel blends the premultiplied color src into the pixel (x,y) with the
+// blend factors of the current call.
+func (ras *softwareRasterizer) blendPixel(x, y int, src [4]float32) {
+	var img = ras.r.img
+	var min = img.Bounds().Min
+	var p = img.Pix[img.PixOffset(min.X+x, min.Y+y):]
+	var dst = [4]float32{float32(p[0]) / 255, float32(p[1]) / 255, float32(p[2]) / 255, float32(p[3]) / 255}
+
+	for i := 0; i < 4; i++ {
+		var srcFactor, dstFactor = ras.blend[0], ras.blend[1]
+		if i == 3 {
+			srcFactor, dstFactor = ras.blend[2], ras.blend[3]
+		}
+		var c = src[i]*blendFactor(srcFactor, i, src, dst) + dst[i]*blendFactor(dstFactor, i, src, dst)
+		p[i] = uint8(clampf(c, 0, 1)*255 + 0.5)
+	}
+}
+
+// blendFactor returns the value of factor for component i, as specified for
+// glBlendFuncSeparate.
+func blendFactor(factor BlendFactor, i int, src, dst [4]float32) float32 {
+	switch factor {
+	case Zero:
+		return 0
+	case One:
+		return 1
+	case SrcColor:
+		return src[i]
+	case OneMinusSrcColor:
+		return 1 - src[i]
+	case DstColor:
+		return dst[i]
+	case OneMinusDstColor:
+		return 1 - dst[i]
+	case SrcAlpha:
+		return src[3]
+	case OneMinusSrcAlpha:
+		return 1 - src[3]
+	case DstAlpha:
+		return dst[3]
+	case OneMinusDstAlpha:
+		return 1 - dst[3]
+	case SrcAlphaSaturate:
+		if i == 3 {
+			return 1
+		}
+		return minf(src[3], 1-dst[3])
+	}
+	return 0
+}
+
+func (frag *softwareUniforms) scissorMask(x, y float32) float32 {
+	var sx, sy = transformPoint(frag.scissorMat, x, y)
+	sx = 0.5 - (absf(sx)-frag.scissorExt[0])*frag.scissorScale[0]
+	sy = 0.5 - (absf(sy)-frag.scissorExt[1])*frag.scissorScale[1]
+	return clampf(sx, 0, 1) * clampf(sy, 0, 1)
+}
+
+// transformPoint is a Go version of TransformPoint for use in inner loops.
+func transformPoint(xform [6]float32, x, y float32) (float32, float32) {
+	return x*xform[0] + y*xform[2] + xform[4], x*xform[1] + y*xform[3] + xform[5]
+}
+
+func sdRoundRect(x, y float32, extent [2]float32, radius float32) float32 {
+	var dx = absf(x) - (extent[0] - radius)
+	var dy = absf(y) - (extent[1] - radius)
+	var ox, oy = maxf(dx, 0), maxf(dy, 0)
+	return minf(maxf(dx, dy), 0) + float32(math.Sqrt(float64(ox*ox+oy*oy))) - radius
+}
+
+func minf(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxf(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func absf(a float32) float32 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func clampf(a, lo, hi float32) float32 {
+	return minf(maxf(a, lo), hi)
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// renderSoftware draws a frame of 100x100 on a white image with the software
+// renderer.
+func renderSoftware(t *testing.T, flags CreateFlag, fn func(ctx *Context)) *image.RGBA {
+	t.Helper()
+	var img = image.NewRGBA(image.Rect(0, 0, 100, 100))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	var ctx = CreateSoftwareContext(img, flags)
+	if ctx == nil {
+		t.Fatal("CreateSoftwareContext failed")
+	}
+	defer ctx.Delete()
+	ctx.BeginFrame(100, 100, 1)
+	fn(ctx)
+	ctx.EndFrame()
+	return img
+}
+
+type pixelTest struct {
+	x, y int
+	want color.RGBA
+}
+
+func checkPixels(t *testing.T, img *image.RGBA, tests []pixelTest) {
+	t.Helper()
+	var near = func(a, b uint8) bool {
+		var d = int(a) - int(b)
+		return d >= -2 && d <= 2
+	}
+	for _, test := range tests {
+		var got = img.RGBAAt(test.x, test.y)
+		if !near(got.R, test.want.R) || !near(got.G, test.want.G) || !near(got.B, test.want.B) || !near(got.A, test.want.A) {
+			t.Errorf("pixel (%d,%d) = %v, want %v", test.x, test.y, got, test.want)
+		}
+	}
+}
+
+var (
+	white       = color.RGBA{255, 255, 255, 255}
+	red         = color.RGBA{255, 0, 0, 255}
+	blue        = color.RGBA{0, 0, 255, 255}
+	black       = color.RGBA{0, 0, 0, 255}
+	transparent = color.RGBA{}
+)
+
+func TestSoftwareConvexFill(t *testing.T) {
+	var img = renderSoftware(t, Antialias, func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(10, 10, 40, 30)
+		ctx.FillColor(red)
+		ctx.Fill()
+	})
+	checkPixels(t, img, []pixelTest{
+		{10, 10, red},
+		{49, 39, red},
+		{30, 25, red},
+		{9, 25, white},
+		{50, 25, white},
+		{30, 40, white},
+	})
+}
+
+func TestSoftwareConcaveFill(t *testing.T) {
+	var img = renderSoftware(t, Antialias, func(ctx *Context) {
+		// An L shape with a square hole, filled with the nonzero rule.
+		ctx.BeginPath()
+		ctx.MoveTo(10, 10)
+		ctx.LineTo(90, 10)
+		ctx.LineTo(90, 40)
+		ctx.LineTo(40, 40)
+		ctx.LineTo(40, 90)
+		ctx.LineTo(10, 90)
+		ctx.ClosePath()
+		ctx.Rect(15, 15, 10, 10)
+		ctx.PathWinding(Hole)
+		ctx.FillColor(blue)
+		ctx.Fill()
+	})
+	checkPixels(t, img, []pixelTest{
+		{80, 20, blue},
+		{20, 80, blue},
+		{30, 30, blue},
+		{20, 20, white},
+		{60, 60, white},
+		{5, 5, white},
+	})
+}
+
+func TestSoftwareStroke(t *testing.T) {
+	var img = renderSoftware(t, Antialias|StencilStrokes, func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.MoveTo(10, 50)
+		ctx.LineTo(90, 50)
+		ctx.StrokeWidth(10)
+		ctx.StrokeColor(black)
+		ctx.Stroke()
+	})
+	checkPixels(t, img, []pixelTest{
+		{50, 50, black},
+		{50, 45, black},
+		{50, 54, black},
+		{50, 40, white},
+		{50, 60, white},
+		// Butt caps end at the end points.
+		{8, 50, white},
+		{92, 50, white},
+	})
+}
+
+func TestSoftwareLinearGradientStops(t *testing.T) {
+	var img = renderSoftware(t, Antialias, func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 100, 100)
+		ctx.FillPaint(ctx.LinearGradientStops(0, 0, 100, 0, GradientStops{
+			{0, red},
+			{0.25, color.RGBA{0, 255, 0, 255}},
+			{1, blue},
+		}))
+		ctx.Fill()
+	})
+	checkPixels(t, img, []pixelTest{
+		{0, 50, color.RGBA{250, 5, 0, 255}},
+		{25, 50, color.RGBA{0, 253, 2, 255}},
+		{50, 50, color.RGBA{0, 168, 87, 255}},
+		{99, 50, color.RGBA{0, 2, 253, 255}},
+	})
+}
+
+func TestSoftwareGradientPaintReuse(t *testing.T) {
+	var img = image.NewRGBA(image.Rect(0, 0, 100, 100))
+	var ctx = CreateSoftwareContext(img, Antialias)
+	if ctx == nil {
+		t.Fatal("CreateSoftwareContext failed")
+	}
+	defer ctx.Delete()
+	var stops = GradientStops{{1, blue}, {0, red}}
+	ctx.BeginFrame(100, 100, 1)
+	var paint = ctx.LinearGradientStops(0, 0, 100, 0, stops)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 100, 50)
+	ctx.FillPaint(paint)
+	ctx.Fill()
+	ctx.EndFrame()
+	// Paints stay valid after the frame they were created in.
+	for i := 0; i < 2; i++ {
+		ctx.BeginFrame(100, 100, 1)
+		ctx.BeginPath()
+		ctx.Rect(0, 50, 100, 50)
+		ctx.FillPaint(paint)
+		ctx.Fill()
+		ctx.EndFrame()
+	}
+	checkPixels(t, img, []pixelTest{
+		{0, 25, color.RGBA{254, 0, 1, 255}},
+		{99, 25, color.RGBA{1, 0, 254, 255}},
+		{0, 75, color.RGBA{254, 0, 1, 255}},
+		{99, 75, color.RGBA{1, 0, 254, 255}},
+	})
+	var got = paint.Stops()
+	if len(got) != 2 || got[0].Offset != 0 || got[1].Offset != 1 {
+		t.Errorf("Stops() = %v, want sorted %v", got, stops)
+	}
+}
+
+func TestSoftwareScissor(t *testing.T) {
+	var img = renderSoftware(t, Antialias, func(ctx *Context) {
+		ctx.Scissor(20, 30, 40, 20)
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 100, 100)
+		ctx.FillColor(red)
+		ctx.Fill()
+	})
+	checkPixels(t, img, []pixelTest{
+		{20, 30, red},
+		{59, 49, red},
+		{19, 40, white},
+		{60, 40, white},
+		{40, 29, white},
+		{40, 50, white},
+	})
+}
+
+func TestSoftwareCompositeOperation(t *testing.T) {
+	var img = renderSoftware(t, Antialias, func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(10, 10, 80, 80)
+		ctx.FillColor(red)
+		ctx.Fill()
+		// DestinationOut cuts the source shape out of the destination.
+		ctx.GlobalCompositeOperation(DestinationOut)
+		ctx.BeginPath()
+		ctx.Rect(30, 30, 40, 40)
+		ctx.FillColor(blue)
+		ctx.Fill()
+	})
+	checkPixels(t, img, []pixelTest{
+		{20, 20, red},
+		{50, 50, transparent},
+		{5, 5, white},
+	})
+}
@@ -0,0 +1,795 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+)
+
+// SVGRenderer is a Renderer which writes each frame as an SVG document.
+//
+// Fills are written as polygons and strokes as the outlines of their
+// tessellation, so the context should be created without Antialias, which
+// would inset the polygons by half a pixel. Linear and radial gradients
+// become SVG gradients, other gradients are rasterized into images. Image
+// patterns are written with the images embedded as PNG, and text as text
+// elements with the fonts created by Context.CreateFont() or
+// Context.CreateFontMem() embedded. Scissors become clip paths, and composite
+// operations are mapped to mix-blend-mode where possible.
+//
+//     var svg = nanovgo.NewSVGRenderer(file)
+//     var ctx = nanovgo.CreateContextWithRenderer(svg, 0)
+type SVGRenderer struct {
+	// Recorder records the frames which are written as SVG.
+	*Recorder
+
+	w   io.Writer
+	err error
+	// texts are the text runs of the frame being drawn.
+	texts []svgText
+}
+
+// svgText is a text run drawn before the command with index at of the frame.
+type svgText struct {
+	at      int
+	paint   RenderPaint
+	blend   BlendState
+	scissor Scissor
+	run     textRun
+}
+
+// NewSVGRenderer creates an SVGRenderer which writes the SVG document of each
+// frame to w.
+func NewSVGRenderer(w io.Writer) *SVGRenderer {
+	return &SVGRenderer{
+		Recorder: NewRecorder(),
+		w:        w,
+	}
+}
+
+// SetWriter sets the writer of the following frames, which can be used to
+// write each frame to its own file.
+func (r *SVGRenderer) SetWriter(w io.Writer) {
+	r.w = w
+}
+
+// Err returns the first error which occurred when writing a frame.
+func (r *SVGRenderer) Err() error {
+	return r.err
+}
+
+// Viewport implements Renderer. It starts a new frame.
+func (r *SVGRenderer) Viewport(width, height, devicePixelRatio float32) {
+	r.Recorder.Viewport(width, height, devicePixelRatio)
+	r.texts = nil
+}
+
+// Cancel implements Renderer. It discards the frame being drawn.
+func (r *SVGRenderer) Cancel() {
+	r.Recorder.Cancel()
+	r.texts = nil
+}
+
+// Flush implements Renderer. It writes the frame as an SVG document.
+func (r *SVGRenderer) Flush() {
+	r.Recorder.Flush()
+	var texts = r.texts
+	r.texts = nil
+	if r.err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	newSVGWriter(&buf, r.Recorder).writeFrame(r.Frame(), texts)
+	_, r.err = r.w.Write(buf.Bytes())
+}
+
+// drawText implements textRenderer.
+func (r *SVGRenderer) drawText(paint *RenderPaint, op CompositeOperationState, scissor *Scissor, run *textRun) {
+	r.texts = append(r.texts, svgText{
+		at:      len(r.Recorder.current.Commands),
+		paint:   *paint,
+		blend:   recordBlend(op),
+		scissor: *scissor,
+		run:     *run,
+	})
+}
+
+type svgWriter struct {
+	buf      *bytes.Buffer
+	recorder *Recorder
+	scale    float32
+	nextID   int
+	// images maps textures to the IDs of their embedded images.
+	images map[int]string
+	// masks maps alpha textures to the IDs of masks using them.
+	masks map[int]string
+	// fonts maps fonts to the families of their embedded font faces.
+	fonts map[*textFont]string
+}
+
+func newSVGWriter(buf *bytes.Buffer, recorder *Recorder) *svgWriter {
+	return &svgWriter{
+		buf:      buf,
+		recorder: recorder,
+		images:   make(map[int]string),
+		masks:    make(map[int]string),
+		fonts:    make(map[*textFont]string),
+	}
+}
+
+func (w *svgWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(w.buf, format, args...)
+}
+
+func (w *svgWriter) newID(prefix string) string {
+	w.nextID++
+	return prefix + strconv.Itoa(w.nextID)
+}
+
+// formatNumber formats f with at most three decimals, for the SVG and PDF
+// output.
+func formatNumber(f float32) string {
+	var rounded = math.Round(float64(f)*1000) / 1000
+	if rounded == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+func svgMatrix(xform [6]float32) string {
+	return fmt.Sprintf("matrix(%s %s %s %s %s %s)",
+		formatNumber(xform[0]), formatNumber(xform[1]), formatNumber(xform[2]),
+		formatNumber(xform[3]), formatNumber(xform[4]), formatNumber(xform[5]))
+}
+
+// svgColor returns the color and opacity of c, which is not premultiplied.
+func svgColor(c [4]float32) (color, opacity string) {
+	var to8 = func(f float32) int {
+		return int(clampf(f, 0, 1)*255 + 0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", to8(c[0]), to8(c[1]), to8(c[2])), formatNumber(clampf(c[3], 0, 1))
+}
+
+// svgBlendMode returns the mix-blend-mode matching blend, or an empty string
+// for normal blending and composite operations SVG cannot express.
+func svgBlendMode(blend BlendState) string {
+	switch {
+	case blend.SrcRGB == One && blend.DstRGB == One:
+		return "plus-lighter"
+	case blend.SrcRGB == DstColor && blend.DstRGB == OneMinusSrcAlpha:
+		return "multiply"
+	case blend.SrcRGB == One && blend.DstRGB == OneMinusSrcColor:
+		return "screen"
+	}
+	return ""
+}
+
+// writeFrame writes frame with the text runs drawn between its commands.
+func (w *svgWriter) writeFrame(frame *Frame, texts []svgText) {
+	w.scale = frame.DevicePixelRatio
+	if w.scale <= 0 {
+		w.scale = 1
+	}
+	w.printf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	w.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n",
+		formatNumber(frame.Width), formatNumber(frame.Height), formatNumber(frame.Width), formatNumber(frame.Height))
+	for i := range frame.Commands {
+		for len(texts) > 0 && texts[0].at <= i {
+			w.writeText(&texts[0])
+			texts = texts[1:]
+		}
+		w.writeCommand(&frame.Commands[i])
+	}
+	for i := range texts {
+		w.writeText(&texts[i])
+	}
+	w.printf("</svg>\n")
+}
+
+// writeGroup writes the elements written by fn in a group clipped to scissor
+// and blended with blend, if needed.
+func (w *svgWriter) writeGroup(scissor *Scissor, blend BlendState, fn func()) {
+	var attrs string
+	if scissor.Extent[0] >= -0.5 && scissor.Extent[1] >= -0.5 {
+		attrs += fmt.Sprintf(" clip-path=\"url(#%s)\"", w.writeClipPath(scissor))
+	}
+	if mode := svgBlendMode(blend); mode != "" {
+		attrs += fmt.Sprintf(" style=\"mix-blend-mode:%s\"", mode)
+	}
+	if attrs != "" {
+		w.printf("<g%s>\n", attrs)
+	}
+	fn()
+	if attrs != "" {
+		w.printf("</g>\n")
+	}
+}
+
+func (w *svgWriter) writeCommand(cmd *Command) {
+	w.writeGroup(&cmd.Scissor, cmd.Blend, func() {
+		w.writeCommandElements(cmd)
+	})
+}
+
+func (w *svgWriter) writeCommandElements(cmd *Command) {
+	switch cmd.Type {
+	case FillCommand:
+		var d bytes.Buffer
+		for _, path := range cmd.Paths {
+			writePolygon(&d, svgPolygonFormat, path.Fill)
+		}
+		w.writePath(d.String(), &cmd.Paint, cmd.Bounds)
+	case StrokeCommand:
+		var d bytes.Buffer
+		var bounds = emptyBounds()
+		for _, path := range cmd.Paths {
+			writeStripOutline(&d, svgPolygonFormat, path.Stroke, path.Closed)
+			for _, v := range path.Stroke {
+				bounds = addBounds(bounds, v.X, v.Y)
+			}
+		}
+		if d.Len() > 0 {
+			w.writePath(d.String(), &cmd.Paint, bounds)
+		}
+	case TrianglesCommand:
+		w.writeTriangles(cmd)
+	}
+}
+
+// polygonFormat are the formats of the path operators of a polygon, which
+// take the formatted coordinates of a point.
+type polygonFormat struct {
+	moveTo, lineTo, close string
+}
+
+var svgPolygonFormat = polygonFormat{moveTo: "M%s %s", lineTo: "L%s %s", close: "Z"}
+
+// writePolygon writes the path of the polygon verts to d. Polygons with less
+// than three vertices are skipped.
+func writePolygon(d io.Writer, format polygonFormat, verts []Vertex) {
+	if len(verts) < 3 {
+		return
+	}
+	for i, v := range verts {
+		var op = format.lineTo
+		if i == 0 {
+			op = format.moveTo
+		}
+		fmt.Fprintf(d, op, formatNumber(v.X), formatNumber(v.Y))
+	}
+	io.WriteString(d, format.close)
+}
+
+// writeStripOutline writes the outline of the triangle strip verts of a
+// stroke to d, which goes along the vertices on one side of the strip and back
+// along the other. Filled with the non-zero rule, the outline covers the strip
+// like its triangles, without seams between them.
+//
+// Triangles which are flipped relative to the others, as at inner joins,
+// would cancel out the area they overlap, so the strip is split at them and
+// they are written on their own, with all polygons in the same direction. The
+// strips of closed paths without such triangles end with their first
+// vertices, so their sides are written as two polygons.
+func writeStripOutline(d io.Writer, format polygonFormat, verts []Vertex, closed bool) {
+	var n = len(verts)
+	if n < 3 {
+		return
+	}
+	// The areas of the triangles, negated for every other one, which have
+	// their vertices in the opposite order.
+	var areas = make([]float32, n-2)
+	var total float32
+	for i := range areas {
+		var v0, v1, v2 = verts[i], verts[i+1], verts[i+2]
+		areas[i] = (v1.X-v0.X)*(v2.Y-v0.Y) - (v1.Y-v0.Y)*(v2.X-v0.X)
+		if i%2 == 1 {
+			areas[i] = -areas[i]
+		}
+		total += areas[i]
+	}
+	var flipped = func(i int) bool {
+		return areas[i]*total < 0
+	}
+
+	var start = 0
+	var split = false
+	for i := range areas {
+		if flipped(i) {
+			writeSubStripOutline(d, format, verts[start:i+2])
+			writeTriangle(d, format, verts[i], verts[i+1], verts[i+2])
+			start = i + 1
+			split = true
+		}
+	}
+	if split || !closed || n < 6 {
+		writeSubStripOutline(d, format, verts[start:])
+		return
+	}
+	var side0, side1 []Vertex
+	for i := 0; i+3 < n; i += 2 {
+		side0 = append(side0, verts[i])
+		side1 = append(side1, verts[n-3-i])
+	}
+	writePolygon(d, format, side0)
+	writePolygon(d, format, side1)
+}
+
+// writeSubStripOutline writes the outline of a triangle strip without flipped
+// triangles as one counter-clockwise polygon.
+func writeSubStripOutline(d io.Writer, format polygonFormat, verts []Vertex) {
+	if len(verts) < 3 {
+		return
+	}
+	var outline = make([]Vertex, 0, len(verts))
+	for i := 0; i < len(verts); i += 2 {
+		outline = append(outline, verts[i])
+	}
+	for i := len(verts) - 1 - len(verts)%2; i > 0; i -= 2 {
+		outline = append(outline, verts[i])
+	}
+	var area float32
+	for i, v0 := range outline {
+		var v1 = outline[(i+1)%len(outline)]
+		area += v0.X*v1.Y - v1.X*v0.Y
+	}
+	if area < 0 {
+		for i, j := 0, len(outline)-1; i < j; i, j = i+1, j-1 {
+			outline[i], outline[j] = outline[j], outline[i]
+		}
+	}
+	writePolygon(d, format, outline)
+}
+
+// writeTriangle writes the path of a triangle to d, with the vertices in
+// counter-clockwise order so that the triangles of a strip do not cancel each
+// other out with the nonzero rule. Degenerate triangles are skipped.
+func writeTriangle(d io.Writer, format polygonFormat, v0, v1, v2 Vertex) {
+	var area = (v1.X-v0.X)*(v2.Y-v0.Y) - (v1.Y-v0.Y)*(v2.X-v0.X)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		v1, v2 = v2, v1
+	}
+	writePolygon(d, format, []Vertex{v0, v1, v2})
+}
+
+func (w *svgWriter) writeClipPath(s *Scissor) string {
+	var id = w.newID("clip")
+	w.printf("<defs><clipPath id=\"%s\"><rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" transform=\"%s\"/></clipPath></defs>\n",
+		id, formatNumber(-s.Extent[0]), formatNumber(-s.Extent[1]), formatNumber(2*s.Extent[0]), formatNumber(2*s.Extent[1]), svgMatrix(s.Xform))
+	return id
+}
+
+// writePath writes path data d filled with paint. bounds are the bounds of the
+// path, which are used for paints that are rasterized.
+func (w *svgWriter) writePath(d string, paint *RenderPaint, bounds [4]float32) {
+	var fill, opacity = w.writePaint(paint, bounds)
+	w.printf("<path d=\"%s\" fill=\"%s\"", d, fill)
+	if opacity != "1" {
+		w.printf(" fill-opacity=\"%s\"", opacity)
+	}
+	w.printf("/>\n")
+}
+
+// writePaint writes the definitions needed by paint, and returns the value of
+// the fill attribute and the fill opacity.
+func (w *svgWriter) writePaint(paint *RenderPaint, bounds [4]float32) (fill, opacity string) {
+	if paint.Gradient == GradientLinear || paint.Gradient == GradientRadial {
+		return "url(#" + w.writeGradientStops(paint) + ")", formatNumber(clampf(paint.InnerColor[3], 0, 1))
+	}
+	if paint.Gradient == GradientConic {
+		if id := w.writeRasterizedPaint(paint, bounds); id != "" {
+			return "url(#" + id + ")", "1"
+		}
+		return "none", "1"
+	}
+	if paint.Image != 0 {
+		if id := w.writeImagePattern(paint); id != "" {
+			return "url(#" + id + ")", formatNumber(clampf(paint.InnerColor[3], 0, 1))
+		}
+	}
+	if paint.InnerColor == paint.OuterColor || paint.Image != 0 {
+		return svgColor(paint.InnerColor)
+	}
+
+	var id string
+	switch {
+	case paint.Radius == 0 && paint.Extent[0] >= 1e4:
+		id = w.writeLinearGradient(paint)
+	case paint.Extent[0] == paint.Extent[1] && paint.Radius == paint.Extent[0]:
+		id = w.writeRadialGradient(paint)
+	default:
+		id = w.writeRasterizedPaint(paint, bounds)
+	}
+	if id == "" {
+		return svgColor(paint.InnerColor)
+	}
+	return "url(#" + id + ")", "1"
+}
+
+// writeStops writes gradient stops at the offsets at which the gradient from
+// inner to outer has the mix ratios t0 and t1.
+func (w *svgWriter) writeStops(paint *RenderPaint, offset0, t0, offset1, t1 float32) {
+	for _, stop := range [][2]float32{{offset0, t0}, {offset1, t1}} {
+		var c [4]float32
+		for i := range c {
+			c[i] = paint.InnerColor[i]*(1-stop[1]) + paint.OuterColor[i]*stop[1]
+		}
+		var color, opacity = svgColor(c)
+		w.printf("<stop offset=\"%s\" stop-color=\"%s\" stop-opacity=\"%s\"/>", formatNumber(stop[0]), color, opacity)
+	}
+}
+
+func (w *svgWriter) writeLinearGradient(paint *RenderPaint) string {
+	// The gradient runs along the y axis of the paint space, centered at
+	// Extent[1]. The paint transform is moved there to keep numbers small.
+	var xform = paint.Xform
+	xform[4] += xform[2] * paint.Extent[1]
+	xform[5] += xform[3] * paint.Extent[1]
+	var feather = maxf(paint.Feather, 1)
+	var id = w.newID("gradient")
+	w.printf("<defs><linearGradient id=\"%s\" gradientUnits=\"userSpaceOnUse\" x1=\"0\" y1=\"%s\" x2=\"0\" y2=\"%s\" gradientTransform=\"%s\">",
+		id, formatNumber(-feather*0.5), formatNumber(feather*0.5), svgMatrix(xform))
+	w.writeStops(paint, 0, 0, 1, 1)
+	w.printf("</linearGradient></defs>\n")
+	return id
+}
+
+func (w *svgWriter) writeRadialGradient(paint *RenderPaint) string {
+	var feather = maxf(paint.Feather, 1)
+	var outer = paint.Radius + feather*0.5
+	if outer <= 0 {
+		return ""
+	}
+	// The mix ratio is 0 at Radius-Feather/2 and 1 at Radius+Feather/2. When
+	// the inner radius is negative, the gradient starts mixed at the center.
+	var offset0, t0 = (paint.Radius - feather*0.5) / outer, float32(0)
+	if offset0 < 0 {
+		offset0, t0 = 0, clampf((feather*0.5-paint.Radius)/feather, 0, 1)
+	}
+	var id = w.newID("gradient")
+	w.printf("<defs><radialGradient id=\"%s\" gradientUnits=\"userSpaceOnUse\" cx=\"0\" cy=\"0\" r=\"%s\" gradientTransform=\"%s\">",
+		id, formatNumber(outer), svgMatrix(paint.Xform))
+	w.writeStops(paint, offset0, t0, 1, 1)
+	w.printf("</radialGradient></defs>\n")
+	return id
+}
+
+// writeGradientStops writes a gradient with color stops, and returns its ID.
+func (w *svgWriter) writeGradientStops(paint *RenderPaint) string {
+	var id = w.newID("gradient")
+	var element = "linearGradient"
+	// The offsets of radial gradients start at the inner radius.
+	var offset0, scale = float32(0), float32(1)
+	if paint.Gradient == GradientRadial {
+		element = "radialGradient"
+		offset0 = paint.Radius / paint.Extent[0]
+		scale = 1 - offset0
+		w.printf("<defs><radialGradient id=\"%s\" gradientUnits=\"userSpaceOnUse\" cx=\"0\" cy=\"0\" r=\"%s\" gradientTransform=\"%s\">",
+			id, formatNumber(paint.Extent[0]), svgMatrix(paint.Xform))
+	} else {
+		w.printf("<defs><linearGradient id=\"%s\" gradientUnits=\"userSpaceOnUse\" x1=\"0\" y1=\"0\" x2=\"%s\" y2=\"0\" gradientTransform=\"%s\">",
+			id, formatNumber(paint.Extent[0]), svgMatrix(paint.Xform))
+	}
+	for _, stop := range paint.Stops {
+		var color, opacity = svgColor(stop.Color)
+		w.printf("<stop offset=\"%s\" stop-color=\"%s\" stop-opacity=\"%s\"/>", formatNumber(offset0+stop.Offset*scale), color, opacity)
+	}
+	w.printf("</%s></defs>\n", element)
+	return id
+}
+
+// writeRasterizedPaint writes a gradient SVG cannot express as an image
+// pattern covering bounds.
+func (w *svgWriter) writeRasterizedPaint(paint *RenderPaint, bounds [4]float32) string {
+	var img = rasterizePaint(paint, bounds, w.scale)
+	if img == nil {
+		return ""
+	}
+	var width, height = bounds[2] - bounds[0], bounds[3] - bounds[1]
+	var id = w.newID("pattern")
+	w.printf("<defs><pattern id=\"%s\" patternUnits=\"userSpaceOnUse\" x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\"><image width=\"%s\" height=\"%s\" preserveAspectRatio=\"none\" xlink:href=\"%s\"/></pattern></defs>\n",
+		id, formatNumber(bounds[0]), formatNumber(bounds[1]), formatNumber(width), formatNumber(height),
+		formatNumber(width), formatNumber(height), svgDataURI(img))
+	return id
+}
+
+// rasterizePaint renders a gradient paint into an image covering bounds, with
+// scale pixels per unit, and at most 2048 pixels in each direction. Returns
+// nil if bounds are empty.
+func rasterizePaint(paint *RenderPaint, bounds [4]float32, scale float32) *image.RGBA {
+	const maxSize = 2048
+	var width, height = bounds[2] - bounds[0], bounds[3] - bounds[1]
+	if !(width > 0 && height > 0) {
+		return nil
+	}
+	scale = minf(scale, maxSize/width)
+	scale = minf(scale, maxSize/height)
+	var img = image.NewRGBA(image.Rect(0, 0, int(math.Ceil(float64(width*scale))), int(math.Ceil(float64(height*scale)))))
+	var inv = inverseXform(paint.Xform)
+	var feather = maxf(paint.Feather, 1)
+	var inner, outer = premulColor(paint.InnerColor), premulColor(paint.OuterColor)
+	var ramp []byte
+	if paint.Gradient != GradientNone {
+		ramp = gradientRamp(paint)
+	}
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			var px, py = transformPoint(inv, bounds[0]+(float32(x)+0.5)/scale, bounds[1]+(float32(y)+0.5)/scale)
+			var color [4]float32
+			if ramp != nil {
+				color = rampColor(ramp, gradientOffset(paint, px, py))
+				for i := range color {
+					color[i] *= inner[i]
+				}
+			} else {
+				var t = clampf((sdRoundRect(px, py, paint.Extent, paint.Radius)+feather*0.5)/feather, 0, 1)
+				for i := range color {
+					color[i] = inner[i]*(1-t) + outer[i]*t
+				}
+			}
+			var pix = img.Pix[y*img.Stride+x*4:]
+			for i := range color {
+				pix[i] = uint8(clampf(color[i], 0, 1)*255 + 0.5)
+			}
+		}
+	}
+	return img
+}
+
+// writeImagePattern writes an image pattern paint, and returns its ID or an
+// empty string if the texture does not exist.
+func (w *svgWriter) writeImagePattern(paint *RenderPaint) string {
+	var tex = w.recorder.Texture(paint.Image)
+	var image = w.writeImage(paint.Image)
+	if tex == nil || image == "" || paint.Extent[0] == 0 || paint.Extent[1] == 0 {
+		return ""
+	}
+	var filter string
+	if tint := paint.InnerColor; tint[0] != 1 || tint[1] != 1 || tint[2] != 1 {
+		filter = fmt.Sprintf(" filter=\"url(#%s)\"", w.writeTintFilter(tint))
+	}
+	var id = w.newID("pattern")
+	w.printf("<defs><pattern id=\"%s\" patternUnits=\"userSpaceOnUse\" width=\"%s\" height=\"%s\" patternTransform=\"%s\"><use xlink:href=\"#%s\" transform=\"scale(%s %s)\"%s/></pattern></defs>\n",
+		id, formatNumber(paint.Extent[0]), formatNumber(paint.Extent[1]), svgMatrix(paint.Xform),
+		image, formatNumber(paint.Extent[0]/float32(tex.Width)), formatNumber(paint.Extent[1]/float32(tex.Height)), filter)
+	return id
+}
+
+// writeTintFilter writes a filter multiplying colors by the RGB components of
+// tint, and returns its ID.
+func (w *svgWriter) writeTintFilter(tint [4]float32) string {
+	var id = w.newID("tint")
+	w.printf("<defs><filter id=\"%s\" color-interpolation-filters=\"sRGB\"><feColorMatrix type=\"matrix\" values=\"%s 0 0 0 0 0 %s 0 0 0 0 0 %s 0 0 0 0 0 1 0\"/></filter></defs>\n",
+		id, formatNumber(clampf(tint[0], 0, 1)), formatNumber(clampf(tint[1], 0, 1)), formatNumber(clampf(tint[2], 0, 1)))
+	return id
+}
+
+// writeImage embeds the texture image once per document, and returns its ID
+// or an empty string if the texture does not exist.
+func (w *svgWriter) writeImage(image int) string {
+	if id, ok := w.images[image]; ok {
+		return id
+	}
+	var tex = w.recorder.Texture(image)
+	if tex == nil || tex.Width <= 0 || tex.Height <= 0 {
+		return ""
+	}
+	var id = w.newID("image")
+	w.printf("<defs><image id=\"%s\" width=\"%d\" height=\"%d\" preserveAspectRatio=\"none\" xlink:href=\"%s\"/></defs>\n",
+		id, tex.Width, tex.Height, svgDataURI(textureImage(tex)))
+	w.images[image] = id
+	return id
+}
+
+// textureImage converts a texture to an image in top to bottom row order.
+// Alpha textures become white images with alpha, which work both as images and
+// as luminance masks.
+func textureImage(tex *RecordedTexture) image.Image {
+	var rect = image.Rect(0, 0, tex.Width, tex.Height)
+	var img image.Image
+	var pix []byte
+	var stride int
+	switch {
+	case tex.Type != TextureRGBA:
+		var alpha = image.NewAlpha(rect)
+		img, pix, stride = alpha, alpha.Pix, alpha.Stride
+	case tex.Flags&ImagePremultiplied != 0:
+		var rgba = image.NewRGBA(rect)
+		img, pix, stride = rgba, rgba.Pix, rgba.Stride
+	default:
+		var nrgba = image.NewNRGBA(rect)
+		img, pix, stride = nrgba, nrgba.Pix, nrgba.Stride
+	}
+	for y := 0; y < tex.Height; y++ {
+		var src = y
+		if tex.Flags&ImageFlipy != 0 {
+			src = tex.Height - 1 - y
+		}
+		copy(pix[y*stride:y*stride+stride], tex.Data[src*stride:])
+	}
+	return img
+}
+
+func svgDataURI(img image.Image) string {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// writeTriangles writes textured triangles, which NanoVG uses for text drawn
+// by renderers without fonts, such as the Recorder. The texture is written as
+// a mask in texture pixel space, and each run of
+// triangles with the same mapping from texture to screen space is written as a
+// colored path through the mask.
+func (w *svgWriter) writeTriangles(cmd *Command) {
+	var tex = w.recorder.Texture(cmd.Paint.Image)
+	if tex == nil || tex.Type == TextureRGBA {
+		// Without a mask the triangles are written with the paint.
+		var d bytes.Buffer
+		for i := 2; i < len(cmd.Vertices); i += 3 {
+			writeTriangle(&d, svgPolygonFormat, cmd.Vertices[i-2], cmd.Vertices[i-1], cmd.Vertices[i])
+		}
+		if d.Len() > 0 {
+			var fill, opacity = svgColor(cmd.Paint.InnerColor)
+			w.printf("<path d=\"%s\" fill=\"%s\" fill-opacity=\"%s\"/>\n", d.String(), fill, opacity)
+		}
+		return
+	}
+
+	var mask, ok = w.masks[cmd.Paint.Image]
+	if !ok {
+		var image = w.writeImage(cmd.Paint.Image)
+		mask = w.newID("mask")
+		w.printf("<defs><mask id=\"%s\" maskUnits=\"userSpaceOnUse\" x=\"0\" y=\"0\" width=\"%d\" height=\"%d\"><use xlink:href=\"#%s\"/></mask></defs>\n",
+			mask, tex.Width, tex.Height, image)
+		w.masks[cmd.Paint.Image] = mask
+	}
+	var fill, opacity = svgColor(cmd.Paint.InnerColor)
+
+	var d bytes.Buffer
+	var current [6]float32
+	var flush = func() {
+		if d.Len() > 0 {
+			w.printf("<path d=\"%s\" transform=\"%s\" fill=\"%s\" fill-opacity=\"%s\" mask=\"url(#%s)\"/>\n",
+				d.String(), svgMatrix(current), fill, opacity, mask)
+			d.Reset()
+		}
+	}
+	for i := 2; i < len(cmd.Vertices); i += 3 {
+		var tri = [3]Vertex{cmd.Vertices[i-2], cmd.Vertices[i-1], cmd.Vertices[i]}
+		for j := range tri {
+			tri[j].U *= float32(tex.Width)
+			tri[j].V *= float32(tex.Height)
+		}
+		var xform, ok = textureXform(tri)
+		if !ok {
+			continue
+		}
+		if svgMatrix(xform) != svgMatrix(current) {
+			flush()
+			current = xform
+		}
+		var texTri [3]Vertex
+		for j, v := range tri {
+			texTri[j] = Vertex{X: v.U, Y: v.V}
+		}
+		writeTriangle(&d, svgPolygonFormat, texTri[0], texTri[1], texTri[2])
+	}
+	flush()
+}
+
+// textureXform returns the affine transform mapping the texture coordinates of
+// a triangle to its positions.
+func textureXform(tri [3]Vertex) ([6]float32, bool) {
+	var du1, dv1 = tri[1].U - tri[0].U, tri[1].V - tri[0].V
+	var du2, dv2 = tri[2].U - tri[0].U, tri[2].V - tri[0].V
+	var det = du1*dv2 - du2*dv1
+	if absf(det) < 1e-6 {
+		return [6]float32{}, false
+	}
+	var dx1, dy1 = tri[1].X - tri[0].X, tri[1].Y - tri[0].Y
+	var dx2, dy2 = tri[2].X - tri[0].X, tri[2].Y - tri[0].Y
+	// Solve [a c; b d] * [du dv] = [dx dy] for both edges.
+	var a = (dx1*dv2 - dx2*dv1) / det
+	var c = (dx2*du1 - dx1*du2) / det
+	var b = (dy1*dv2 - dy2*dv1) / det
+	var d = (dy2*du1 - dy1*du2) / det
+	return [6]float32{a, b, c, d, tri[0].X - a*tri[0].U - c*tri[0].V, tri[0].Y - b*tri[0].U - d*tri[0].V}, true
+}
+
+// writeText writes a text run as text elements with the embedded fonts of its
+// glyphs. The elements are in the space of the run, so the paint is
+// transformed into it.
+func (w *svgWriter) writeText(text *svgText) {
+	var run = &text.run
+	if len(run.glyphs) == 0 {
+		return
+	}
+	var paint = text.paint
+	TransformMultiply(&paint.Xform, inverseXform(run.xform))
+	var bounds = emptyBounds()
+	for _, glyph := range run.glyphs {
+		var scale = run.size / float32(glyph.font.ascent-glyph.font.descent)
+		bounds = addBounds(bounds, glyph.x, glyph.y-float32(glyph.font.ascent)*scale)
+		bounds = addBounds(bounds, glyph.x+float32(glyph.advance)*scale, glyph.y-float32(glyph.font.descent)*scale)
+	}
+
+	w.writeGroup(&text.scissor, text.blend, func() {
+		var fill, opacity = w.writePaint(&paint, bounds)
+		// Each run of glyphs of the same font is a text element, with the
+		// glyphs at the positions NanoVG laid them out.
+		for start := 0; start < len(run.glyphs); {
+			var font = run.glyphs[start].font
+			var end = start + 1
+			for end < len(run.glyphs) && run.glyphs[end].font == font {
+				end++
+			}
+			var family = w.writeFont(font)
+			// NanoVG sizes fonts by the height from descender to ascender
+			// instead of the em square.
+			var size = run.size * font.unitsPerEm / float32(font.ascent-font.descent)
+			var xs, ys, chars bytes.Buffer
+			for i, glyph := range run.glyphs[start:end] {
+				if i > 0 {
+					xs.WriteByte(' ')
+					ys.WriteByte(' ')
+				}
+				xs.WriteString(formatNumber(glyph.x))
+				ys.WriteString(formatNumber(glyph.y))
+				chars.WriteRune(glyph.codepoint)
+			}
+			w.printf("<text xml:space=\"preserve\" font-family=\"%s\" font-size=\"%s\" x=\"%s\" y=\"%s\" transform=\"%s\" fill=\"%s\"",
+				family, formatNumber(size), xs.String(), ys.String(), svgMatrix(run.xform), fill)
+			if opacity != "1" {
+				w.printf(" fill-opacity=\"%s\"", opacity)
+			}
+			w.printf(">")
+			xml.EscapeText(w.buf, chars.Bytes())
+			w.printf("</text>\n")
+			start = end
+		}
+	})
+}
+
+// writeFont embeds font as a font face once per document, and returns its
+// family.
+func (w *svgWriter) writeFont(font *textFont) string {
+	if family, ok := w.fonts[font]; ok {
+		return family
+	}
+	var family = w.newID("font")
+	var mime, format = "font/ttf", "truetype"
+	if bytes.HasPrefix(font.data, []byte("OTTO")) {
+		mime, format = "font/otf", "opentype"
+	}
+	w.printf("<defs><style>@font-face{font-family:\"%s\";src:url(data:%s;base64,%s) format(\"%s\")}</style></defs>\n",
+		family, mime, base64.StdEncoding.EncodeToString(font.data), format)
+	w.fonts[font] = family
+	return family
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// writeSVG draws a frame of 100x100 with an SVGRenderer, and returns the SVG
+// document.
+func writeSVG(t *testing.T, fn func(ctx *Context)) string {
+	t.Helper()
+	var buf bytes.Buffer
+	var svg = NewSVGRenderer(&buf)
+	var ctx = CreateContextWithRenderer(svg, 0)
+	if ctx == nil {
+		t.Fatal("CreateContextWithRenderer failed")
+	}
+	defer ctx.Delete()
+	ctx.BeginFrame(100, 100, 1)
+	fn(ctx)
+	ctx.EndFrame()
+	if err := svg.Err(); err != nil {
+		t.Fatalf("writing the SVG failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestSVGRendererStroke(t *testing.T) {
+	var tests = []struct {
+		name  string
+		build func(ctx *Context)
+	}{
+		{"MiterJoins", func(ctx *Context) {
+			ctx.MoveTo(10, 80)
+			ctx.LineTo(30, 20)
+			ctx.LineTo(50, 80)
+			ctx.LineTo(70, 20)
+			ctx.LineTo(90, 80)
+			ctx.StrokeWidth(6)
+		}},
+		{"RoundCapsAndJoins", func(ctx *Context) {
+			ctx.MoveTo(20, 80)
+			ctx.BezierTo(20, 0, 80, 100, 80, 20)
+			ctx.LineCap(RoundCap)
+			ctx.LineJoin(RoundJoin)
+			ctx.StrokeWidth(10)
+		}},
+		{"ClosedBevel", func(ctx *Context) {
+			ctx.Rect(20, 20, 60, 60)
+			ctx.Circle(50, 50, 15)
+			ctx.LineJoin(Bevel)
+			ctx.StrokeWidth(8)
+		}},
+		{"ShortSegments", func(ctx *Context) {
+			ctx.MoveTo(10, 50)
+			ctx.LineTo(50, 50)
+			ctx.LineTo(52, 47)
+			ctx.LineTo(54, 53)
+			ctx.LineTo(90, 50)
+			ctx.StrokeWidth(12)
+		}},
+		// Wide bevel joins flip triangles of the strip.
+		{"WideBevelJoins", func(ctx *Context) {
+			ctx.MoveTo(65, 52)
+			ctx.LineTo(60, 72)
+			ctx.LineTo(47.5, 54.5)
+			ctx.LineTo(49, 53)
+			ctx.LineJoin(Bevel)
+			ctx.StrokeWidth(30)
+		}},
+		{"Dashes", func(ctx *Context) {
+			ctx.Circle(50, 50, 35)
+			ctx.StrokeWidth(6)
+			ctx.LineCap(Square)
+			ctx.StrokeDash([]float32{15, 8}, 0)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var doc = writeSVG(t, func(ctx *Context) {
+				ctx.BeginPath()
+				test.build(ctx)
+				ctx.StrokeColor(blue)
+				ctx.Stroke()
+			})
+			if n := strings.Count(doc, "<path"); n != 1 {
+				t.Errorf("stroke is written as %d paths, want 1", n)
+			}
+			// The outlines cover the stroke with the non-zero rule. Points
+			// less than a pixel from the edges of the stroke are skipped.
+			var d = doc[strings.Index(doc, ` d="`)+4:]
+			var path, err = ParseSVGPath(d[:strings.Index(d, `"`)])
+			if err != nil {
+				t.Fatalf("ParseSVGPath failed: %v", err)
+			}
+			var polygons [][]float32
+			path.walk(func(cmd int, args []float32) {
+				switch cmd {
+				case pathMoveTo:
+					polygons = append(polygons, append([]float32(nil), args...))
+				case pathLineTo:
+					polygons[len(polygons)-1] = append(polygons[len(polygons)-1], args...)
+				}
+			})
+			renderSoftware(t, 0, func(ctx *Context) {
+				ctx.BeginPath()
+				test.build(ctx)
+				for y := float32(0.5); y < 100; y++ {
+					for x := float32(0.5); x < 100; x++ {
+						var inside = ctx.IsPointInStroke(x, y)
+						var edge = false
+						for i := -2; i <= 2 && !edge; i++ {
+							for j := -2; j <= 2 && !edge; j++ {
+								edge = ctx.IsPointInStroke(x+float32(i)*0.5, y+float32(j)*0.5) != inside
+							}
+						}
+						if edge {
+							continue
+						}
+						var winding = 0
+						for _, polygon := range polygons {
+							winding += windingNumber(polygon, x, y)
+						}
+						if (winding != 0) != inside {
+							t.Errorf("point (%v,%v) has winding number %d, inside the stroke is %v", x, y, winding, inside)
+						}
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestSVGRendererText(t *testing.T) {
+	var doc = writeSVG(t, func(ctx *Context) {
+		var font = ctx.CreateFont("sans", "nanovg/example/Roboto-Regular.ttf")
+		if font.cFont < 0 {
+			t.Fatal("CreateFont failed")
+		}
+		ctx.FontFace("sans")
+		ctx.FontSize(20)
+		ctx.FillColor(red)
+		ctx.Text(10, 50, "a<b")
+		ctx.Text(10, 80, "c")
+	})
+	if n := strings.Count(doc, "@font-face"); n != 1 {
+		t.Errorf("the font is embedded %d times, want once", n)
+	}
+	if n := strings.Count(doc, "<text "); n != 2 {
+		t.Errorf("%d text elements are written, want 2", n)
+	}
+	if !strings.Contains(doc, ">a&lt;b</text>") || !strings.Contains(doc, ">c</text>") {
+		t.Errorf("the characters of the text are missing:\n%s", doc)
+	}
+	if strings.Contains(doc, "<image") || strings.Contains(doc, "<mask") {
+		t.Error("the font atlas is embedded")
+	}
+	if !strings.Contains(doc, `fill="#ff0000"`) {
+		t.Error("the text is not filled with red")
+	}
+}
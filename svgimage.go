@@ -0,0 +1,1071 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SVGImage is an SVG document loaded by LoadSVG(), which is drawn as vector
+// shapes by Context.
+//
+// The supported subset of SVG is similar to nanosvg's: <svg> with width,
+// height, viewBox and preserveAspectRatio, <g>, <path>, <rect>, <circle>,
+// <ellipse>, <line>, <polyline> and <polygon>, transforms, fill and stroke
+// properties in attributes and style attributes, dash arrays, and
+// <linearGradient> and <radialGradient> paints. Text, images, <use>, clipping,
+// masks, filters and markers are ignored.
+//
+// The opacity of groups is applied to each shape in them, instead of to the
+// group as a whole, so overlapping shapes in a translucent group show through
+// each other.
+type SVGImage struct {
+	// Width and Height are the size of the image, from the width and height
+	// attributes of the <svg> element, or its viewBox if they are missing.
+	Width, Height float32
+
+	// xform maps the viewBox to the size of the image.
+	xform  [6]float32
+	shapes []*svgShape
+}
+
+// svgShape is a shape of an SVG image with its resolved style.
+type svgShape struct {
+	path        *Path
+	xform       [6]float32
+	bounds      [4]float32
+	fill        svgPaint
+	stroke      svgPaint
+	opacity     float32
+	strokeWidth float32
+	lineCap     LineCap
+	lineJoin    LineJoin
+	miterLimit  float32
+	dashes      []float32
+	dashOffset  float32
+}
+
+// Kinds of SVG paints.
+const (
+	svgPaintNone = iota
+	svgPaintColor
+	svgPaintGradient
+)
+
+// svgPaint is a fill or stroke paint.
+type svgPaint struct {
+	kind     int
+	color    Color
+	gradient *svgGradient
+}
+
+// svgGradient is a resolved gradient paint.
+type svgGradient struct {
+	radial bool
+	// x1, y1, x2, y2 of linear gradients, cx, cy and r of radial ones.
+	x1, y1, x2, y2 float32
+	cx, cy, r      float32
+	xform          [6]float32
+	stops          GradientStops
+}
+
+// svgStyle is the style of an element, including the inherited properties.
+type svgStyle struct {
+	fill, stroke  string
+	fillOpacity   float32
+	strokeOpacity float32
+	fillRule      string
+	strokeWidth   float32
+	lineCap       LineCap
+	lineJoin      LineJoin
+	miterLimit    float32
+	dashes        []float32
+	dashOffset    float32
+	color         Color
+	visible       bool
+	opacity       float32
+	xform         [6]float32
+	stopColor     string
+	stopOpacity   float32
+	display       bool
+}
+
+// svgGradientElement is a gradient element before its references are
+// resolved.
+type svgGradientElement struct {
+	radial bool
+	attrs  map[string]string
+	stops  []GradientStop
+}
+
+// svgParser builds an SVGImage from the tokens of an SVG document.
+type svgParser struct {
+	img       *SVGImage
+	styles    []svgStyle
+	gradients map[string]*svgGradientElement
+	// The gradient whose stops are being read.
+	gradient *svgGradientElement
+	pending  []svgPendingShape
+	// The depth of skipped elements, and of <defs> elements, whose shapes
+	// are not drawn.
+	skipDepth int
+	defsDepth int
+
+	sawRoot     bool
+	viewport    [2]float32
+	viewBox     [4]float32
+	hasViewBox  bool
+	aspectRatio string
+	rootSize    [2]float32
+	rootSizeSet [2]bool
+}
+
+// svgPendingShape is a shape whose paints are resolved when the document has
+// been read, as gradients can be defined after they are used.
+type svgPendingShape struct {
+	shape         *svgShape
+	fill, stroke  string
+	fillOpacity   float32
+	strokeOpacity float32
+	color         Color
+}
+
+// LoadSVG loads an SVG document from r. See SVGImage for the supported
+// features. Documents are read as UTF-8, unless their XML declaration sets
+// the encoding to ISO-8859-1 or US-ASCII; other encodings are not supported.
+func LoadSVG(r io.Reader) (*SVGImage, error) {
+	var p = &svgParser{
+		img:       &SVGImage{},
+		gradients: map[string]*svgGradientElement{},
+		viewport:  [2]float32{100, 100},
+	}
+	var root = svgStyle{
+		fill:          "black",
+		stroke:        "none",
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		fillRule:      "nonzero",
+		strokeWidth:   1,
+		lineCap:       Butt,
+		lineJoin:      Miter,
+		miterLimit:    4,
+		color:         Color{0, 0, 0, 1},
+		visible:       true,
+		opacity:       1,
+		display:       true,
+	}
+	TransformIdentity(&root.xform)
+	p.styles = []svgStyle{root}
+
+	var decoder = xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = svgCharsetReader
+	for {
+		var token, err = decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("nanovgo: invalid SVG document: %v", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			p.startElement(t)
+		case xml.EndElement:
+			p.endElement(t)
+		}
+	}
+	if !p.sawRoot {
+		return nil, fmt.Errorf("nanovgo: no <svg> element in SVG document")
+	}
+	p.finish()
+	return p.img, nil
+}
+
+// svgCharsetReader converts documents in the encodings supported besides
+// UTF-8 to UTF-8. US-ASCII is a subset of ISO-8859-1, whose bytes are the code
+// points of their characters.
+func svgCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "l1", "us-ascii", "ascii":
+		return &latin1Reader{r: input}, nil
+	}
+	return nil, errors.New("unsupported encoding, only UTF-8, ISO-8859-1 and US-ASCII are supported")
+}
+
+// latin1Reader converts ISO-8859-1 text read from r to UTF-8.
+type latin1Reader struct {
+	r   io.Reader
+	buf []byte
+	// The converted text which has not been read yet.
+	out     []byte
+	pending []byte
+	err     error
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	for len(l.pending) == 0 {
+		if l.err != nil || len(p) == 0 {
+			return 0, l.err
+		}
+		if len(l.buf) < len(p) {
+			l.buf = make([]byte, len(p))
+		}
+		var n int
+		n, l.err = l.r.Read(l.buf[:len(p)])
+		l.out = l.out[:0]
+		for _, b := range l.buf[:n] {
+			if b < 0x80 {
+				l.out = append(l.out, b)
+			} else {
+				l.out = append(l.out, 0xc0|b>>6, 0x80|b&0x3f)
+			}
+		}
+		l.pending = l.out
+	}
+	var n = copy(p, l.pending)
+	l.pending = l.pending[n:]
+	return n, nil
+}
+
+func (p *svgParser) startElement(e xml.StartElement) {
+	var attrs = map[string]string{}
+	for _, attr := range e.Attr {
+		attrs[attr.Name.Local] = attr.Value
+	}
+	var parent = p.styles[len(p.styles)-1]
+	p.styles = append(p.styles, parent)
+	if p.skipDepth > 0 || p.gradient != nil && e.Name.Local != "stop" {
+		p.skipDepth++
+		return
+	}
+	var style = p.style(parent, attrs)
+	p.styles[len(p.styles)-1] = style
+
+	switch e.Name.Local {
+	case "svg":
+		if !p.sawRoot {
+			p.sawRoot = true
+			p.root(attrs)
+		}
+		return
+	case "g", "a", "switch":
+		if !style.display {
+			p.skipDepth = 1
+		}
+		return
+	case "linearGradient", "radialGradient":
+		p.gradient = &svgGradientElement{radial: e.Name.Local == "radialGradient", attrs: attrs}
+		if id := attrs["id"]; id != "" {
+			p.gradients[id] = p.gradient
+		}
+		return
+	case "stop":
+		if p.gradient != nil {
+			p.stop(style, attrs)
+		}
+		return
+	case "defs":
+		p.defsDepth++
+		return
+	}
+
+	var path = p.shapePath(e.Name.Local, attrs)
+	if path == nil {
+		// Unknown elements are skipped with their content, which includes
+		// the shapes of <clipPath>, <mask>, <symbol> and <pattern>.
+		p.skipDepth = 1
+		return
+	}
+	if p.defsDepth > 0 || !style.display || !style.visible || path.Empty() {
+		return
+	}
+	p.addShape(path, style, e.Name.Local != "line")
+}
+
+func (p *svgParser) endElement(e xml.EndElement) {
+	p.styles = p.styles[:len(p.styles)-1]
+	if p.skipDepth > 0 {
+		p.skipDepth--
+		return
+	}
+	switch e.Name.Local {
+	case "defs":
+		p.defsDepth--
+	case "linearGradient", "radialGradient":
+		p.gradient = nil
+	}
+}
+
+// root reads the size and viewBox of the <svg> element.
+func (p *svgParser) root(attrs map[string]string) {
+	if vb, ok := attrs["viewBox"]; ok {
+		var nums = parseSVGNumbers(vb)
+		if len(nums) == 4 && nums[2] > 0 && nums[3] > 0 {
+			copy(p.viewBox[:], nums)
+			p.hasViewBox = true
+			p.viewport = [2]float32{nums[2], nums[3]}
+		}
+	}
+	for i, name := range []string{"width", "height"} {
+		var v = strings.TrimSpace(attrs[name])
+		if v == "" || strings.HasSuffix(v, "%") {
+			continue
+		}
+		if f, ok := parseSVGLength(v, 0); ok && f > 0 {
+			p.rootSize[i] = f
+			p.rootSizeSet[i] = true
+		}
+	}
+	if !p.hasViewBox {
+		for i := range p.viewport {
+			if p.rootSizeSet[i] {
+				p.viewport[i] = p.rootSize[i]
+			}
+		}
+	}
+	p.aspectRatio = attrs["preserveAspectRatio"]
+}
+
+// style returns the style of an element from the style of its parent and its
+// attributes.
+func (p *svgParser) style(parent svgStyle, attrs map[string]string) svgStyle {
+	var s = parent
+	// Properties which are not inherited.
+	s.opacity = 1
+	s.display = true
+	s.stopColor = "black"
+	s.stopOpacity = 1
+
+	var properties = map[string]string{}
+	for name, value := range attrs {
+		properties[name] = value
+	}
+	if style, ok := attrs["style"]; ok {
+		for _, decl := range strings.Split(style, ";") {
+			if i := strings.IndexByte(decl, ':'); i >= 0 {
+				properties[strings.TrimSpace(decl[:i])] = strings.TrimSpace(decl[i+1:])
+			}
+		}
+	}
+
+	for name, value := range properties {
+		value = strings.TrimSpace(value)
+		if value == "inherit" {
+			continue
+		}
+		switch name {
+		case "fill":
+			s.fill = value
+		case "stroke":
+			s.stroke = value
+		case "fill-opacity":
+			s.fillOpacity = parseSVGOpacity(value, s.fillOpacity)
+		case "stroke-opacity":
+			s.strokeOpacity = parseSVGOpacity(value, s.strokeOpacity)
+		case "opacity":
+			s.opacity = parseSVGOpacity(value, 1)
+		case "fill-rule":
+			s.fillRule = value
+		case "stroke-width":
+			if f, ok := parseSVGLength(value, p.diagonal()); ok && f >= 0 {
+				s.strokeWidth = f
+			}
+		case "stroke-linecap":
+			switch value {
+			case "butt":
+				s.lineCap = Butt
+			case "round":
+				s.lineCap = RoundCap
+			case "square":
+				s.lineCap = Square
+			}
+		case "stroke-linejoin":
+			switch value {
+			case "miter", "miter-clip", "arcs":
+				s.lineJoin = Miter
+			case "round":
+				s.lineJoin = RoundJoin
+			case "bevel":
+				s.lineJoin = Bevel
+			}
+		case "stroke-miterlimit":
+			if f, err := strconv.ParseFloat(value, 32); err == nil && f >= 1 {
+				s.miterLimit = float32(f)
+			}
+		case "stroke-dasharray":
+			s.dashes = nil
+			if value != "none" {
+				for _, v := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' }) {
+					var f, _ = parseSVGLength(v, p.diagonal())
+					s.dashes = append(s.dashes, f)
+				}
+			}
+		case "stroke-dashoffset":
+			s.dashOffset, _ = parseSVGLength(value, p.diagonal())
+		case "color":
+			if c, err := ParseColor(value); err == nil {
+				s.color = c
+			}
+		case "visibility":
+			s.visible = value == "visible"
+		case "display":
+			s.display = value != "none"
+		case "stop-color":
+			s.stopColor = value
+		case "stop-opacity":
+			s.stopOpacity = parseSVGOpacity(value, 1)
+		}
+	}
+	if t, ok := attrs["transform"]; ok {
+		var xform = parseSVGTransform(t)
+		TransformMultiply(&xform, parent.xform)
+		s.xform = xform
+	}
+	// The opacity of groups is applied to their shapes.
+	s.opacity *= parent.opacity
+	return s
+}
+
+// diagonal returns the normalized diagonal of the viewport, which
+// percentages of lengths which are neither horizontal nor vertical refer to.
+func (p *svgParser) diagonal() float32 {
+	var w, h = p.viewport[0], p.viewport[1]
+	return float32(math.Sqrt(float64(w*w+h*h) / 2))
+}
+
+// stop adds a <stop> to the current gradient.
+func (p *svgParser) stop(style svgStyle, attrs map[string]string) {
+	var offset, _ = parseSVGLength(attrs["offset"], 1)
+	offset = clampf(offset, 0, 1)
+	// Offsets may not decrease.
+	if n := len(p.gradient.stops); n > 0 && offset < p.gradient.stops[n-1].Offset {
+		offset = p.gradient.stops[n-1].Offset
+	}
+	var c = Color{0, 0, 0, 1}
+	if style.stopColor == "currentColor" {
+		c = style.color
+	} else if parsed, err := ParseColor(style.stopColor); err == nil {
+		c = parsed
+	}
+	c.a *= style.stopOpacity
+	p.gradient.stops = append(p.gradient.stops, GradientStop{offset, c})
+}
+
+// shapePath returns the path of a basic shape element, nil if the element is
+// not a shape.
+func (p *svgParser) shapePath(name string, attrs map[string]string) *Path {
+	var w, h, d = p.viewport[0], p.viewport[1], p.diagonal()
+	var length = func(name string, ref float32) float32 {
+		var f, _ = parseSVGLength(attrs[name], ref)
+		return f
+	}
+	var path = &Path{}
+	switch name {
+	case "path":
+		// Paths are rendered up to the first error in their data.
+		path.appendSVGPath(attrs["d"])
+	case "rect":
+		var x, y = length("x", w), length("y", h)
+		var width, height = length("width", w), length("height", h)
+		if width <= 0 || height <= 0 {
+			return path
+		}
+		var rx, okX = parseSVGLength(attrs["rx"], w)
+		var ry, okY = parseSVGLength(attrs["ry"], h)
+		if !okX || rx < 0 {
+			rx, okX = 0, false
+		}
+		if !okY || ry < 0 {
+			ry, okY = 0, false
+		}
+		if !okX {
+			rx = ry
+		}
+		if !okY {
+			ry = rx
+		}
+		svgRect(path, x, y, width, height, minf(rx, width/2), minf(ry, height/2))
+	case "circle":
+		var r = length("r", d)
+		if r > 0 {
+			path.Circle(length("cx", w), length("cy", h), r)
+		}
+	case "ellipse":
+		var rx, ry = length("rx", w), length("ry", h)
+		if rx > 0 && ry > 0 {
+			path.Ellipse(length("cx", w), length("cy", h), rx, ry)
+		}
+	case "line":
+		path.MoveTo(length("x1", w), length("y1", h))
+		path.LineTo(length("x2", w), length("y2", h))
+	case "polyline", "polygon":
+		var nums = parseSVGNumbers(attrs["points"])
+		for i := 0; i+1 < len(nums); i += 2 {
+			if i == 0 {
+				path.MoveTo(nums[i], nums[i+1])
+			} else {
+				path.LineTo(nums[i], nums[i+1])
+			}
+		}
+		if name == "polygon" && len(nums) >= 4 {
+			path.ClosePath()
+		}
+	default:
+		return nil
+	}
+	return path
+}
+
+// svgRect adds a rectangle with elliptical corners of radii rx and ry.
+func svgRect(path *Path, x, y, w, h, rx, ry float32) {
+	if rx <= 0 || ry <= 0 {
+		path.Rect(x, y, w, h)
+		return
+	}
+	var kx, ky = rx * (1 - kappa90), ry * (1 - kappa90)
+	path.MoveTo(x+rx, y)
+	path.LineTo(x+w-rx, y)
+	path.BezierTo(x+w-kx, y, x+w, y+ky, x+w, y+ry)
+	path.LineTo(x+w, y+h-ry)
+	path.BezierTo(x+w, y+h-ky, x+w-kx, y+h, x+w-rx, y+h)
+	path.LineTo(x+rx, y+h)
+	path.BezierTo(x+kx, y+h, x, y+h-ky, x, y+h-ry)
+	path.LineTo(x, y+ry)
+	path.BezierTo(x, y+ky, x+kx, y, x+rx, y)
+	path.ClosePath()
+}
+
+// addShape adds a shape with its style, whose paints are resolved later.
+func (p *svgParser) addShape(path *Path, style svgStyle, fillable bool) {
+	var shape = &svgShape{
+		xform:       style.xform,
+		bounds:      path.Bounds(),
+		opacity:     style.opacity,
+		strokeWidth: style.strokeWidth,
+		lineCap:     style.lineCap,
+		lineJoin:    style.lineJoin,
+		miterLimit:  style.miterLimit,
+		dashOffset:  style.dashOffset,
+	}
+	shape.path = svgFillPath(path, style.fillRule == "evenodd")
+	shape.dashes = append(shape.dashes, style.dashes...)
+	var fill = style.fill
+	if !fillable {
+		fill = "none"
+	}
+	p.pending = append(p.pending, svgPendingShape{
+		shape:         shape,
+		fill:          fill,
+		stroke:        style.stroke,
+		fillOpacity:   style.fillOpacity,
+		strokeOpacity: style.strokeOpacity,
+		color:         style.color,
+	})
+	p.img.shapes = append(p.img.shapes, shape)
+}
+
+// finish resolves the paints of the shapes and computes the size of the
+// image.
+func (p *svgParser) finish() {
+	for _, pending := range p.pending {
+		var shape = pending.shape
+		shape.fill = p.paint(pending.fill, pending.fillOpacity, pending.color, shape.bounds)
+		shape.stroke = p.paint(pending.stroke, pending.strokeOpacity, pending.color, shape.bounds)
+	}
+
+	var img = p.img
+	img.Width, img.Height = p.viewport[0], p.viewport[1]
+	if p.rootSizeSet[0] {
+		img.Width = p.rootSize[0]
+	}
+	if p.rootSizeSet[1] {
+		img.Height = p.rootSize[1]
+	}
+	TransformIdentity(&img.xform)
+	if p.hasViewBox {
+		img.xform = svgViewBoxTransform(p.viewBox, img.Width, img.Height, p.aspectRatio)
+	}
+}
+
+// svgViewBoxTransform returns the transform which maps viewBox to a viewport
+// of size width x height, following preserveAspectRatio.
+func svgViewBoxTransform(viewBox [4]float32, width, height float32, aspectRatio string) [6]float32 {
+	var sx, sy = width / viewBox[2], height / viewBox[3]
+	var fields = strings.Fields(aspectRatio)
+	var align, meetOrSlice = "xMidYMid", "meet"
+	if len(fields) > 0 && fields[0] == "defer" {
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if len(fields) > 1 {
+		meetOrSlice = fields[1]
+	}
+	var tx, ty float32
+	if align != "none" {
+		if meetOrSlice == "slice" {
+			sx = maxf(sx, sy)
+		} else {
+			sx = minf(sx, sy)
+		}
+		sy = sx
+		var extraX, extraY = width - viewBox[2]*sx, height - viewBox[3]*sy
+		switch {
+		case strings.HasPrefix(align, "xMid"):
+			tx = extraX / 2
+		case strings.HasPrefix(align, "xMax"):
+			tx = extraX
+		}
+		switch {
+		case strings.HasSuffix(align, "YMid"):
+			ty = extraY / 2
+		case strings.HasSuffix(align, "YMax"):
+			ty = extraY
+		}
+	}
+	return [6]float32{sx, 0, 0, sy, tx - viewBox[0]*sx, ty - viewBox[1]*sy}
+}
+
+// paint resolves a fill or stroke paint for a shape with bounds.
+func (p *svgParser) paint(value string, opacity float32, current Color, bounds [4]float32) svgPaint {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "" || value == "none":
+		return svgPaint{}
+	case value == "currentColor":
+		current.a *= opacity
+		return svgPaint{kind: svgPaintColor, color: current}
+	case strings.HasPrefix(value, "url("):
+		var end = strings.IndexByte(value, ')')
+		if end < 0 {
+			return svgPaint{}
+		}
+		var id = strings.Trim(strings.TrimSpace(value[4:end]), `'"`)
+		if g := p.resolveGradient(strings.TrimPrefix(id, "#"), opacity, bounds); g != nil {
+			if len(g.stops) == 1 {
+				return svgPaint{kind: svgPaintColor, color: colorOf(g.stops[0].Color)}
+			}
+			return svgPaint{kind: svgPaintGradient, gradient: g}
+		}
+		// Use the fallback color after the reference.
+		return p.paint(value[end+1:], opacity, current, bounds)
+	}
+	var c, err = ParseColor(value)
+	if err != nil {
+		return svgPaint{}
+	}
+	c.a *= opacity
+	return svgPaint{kind: svgPaintColor, color: c}
+}
+
+// resolveGradient resolves the gradient with id for a shape with bounds,
+// following the href references of gradients for missing attributes and
+// stops. It returns nil if there is no such gradient or it has no stops.
+func (p *svgParser) resolveGradient(id string, opacity float32, bounds [4]float32) *svgGradient {
+	var element = p.gradients[id]
+	if element == nil {
+		return nil
+	}
+	var attrs = map[string]string{}
+	var stops []GradientStop
+	var seen = map[*svgGradientElement]bool{}
+	for e := element; e != nil && !seen[e]; {
+		seen[e] = true
+		for name, value := range e.attrs {
+			if _, ok := attrs[name]; !ok {
+				attrs[name] = value
+			}
+		}
+		if stops == nil && len(e.stops) > 0 {
+			stops = e.stops
+		}
+		var href = e.attrs["href"]
+		e = nil
+		if strings.HasPrefix(href, "#") {
+			e = p.gradients[href[1:]]
+		}
+	}
+	if len(stops) == 0 {
+		return nil
+	}
+
+	var g = &svgGradient{radial: element.radial}
+	g.stops = make(GradientStops, len(stops))
+	for i, stop := range stops {
+		var c = colorOf(stop.Color)
+		c.a *= opacity
+		g.stops[i] = GradientStop{stop.Offset, c}
+	}
+
+	var userSpace = attrs["gradientUnits"] == "userSpaceOnUse"
+	var w, h, d = p.viewport[0], p.viewport[1], p.diagonal()
+	if !userSpace {
+		w, h, d = 1, 1, 1
+	}
+	var length = func(name, def string, ref float32) float32 {
+		var value, ok = attrs[name]
+		if !ok {
+			value = def
+		}
+		var f, _ = parseSVGLength(value, ref)
+		return f
+	}
+	if g.radial {
+		g.cx, g.cy, g.r = length("cx", "50%", w), length("cy", "50%", h), length("r", "50%", d)
+	} else {
+		g.x1, g.y1 = length("x1", "0%", w), length("y1", "0%", h)
+		g.x2, g.y2 = length("x2", "100%", w), length("y2", "0%", h)
+	}
+
+	TransformIdentity(&g.xform)
+	if t, ok := attrs["gradientTransform"]; ok {
+		g.xform = parseSVGTransform(t)
+	}
+	if !userSpace {
+		var bw, bh = bounds[2] - bounds[0], bounds[3] - bounds[1]
+		if bw <= 0 || bh <= 0 {
+			// Bounding box units are undefined for shapes without area.
+			return nil
+		}
+		TransformMultiply(&g.xform, [6]float32{bw, 0, 0, bh, bounds[0], bounds[1]})
+	}
+	return g
+}
+
+// Draw draws the image with its top-left corner at (x,y) and scaled by scale,
+// in the current transform of ctx, faded by the current global alpha. The
+// fill, stroke and global alpha styles of ctx are changed and restored
+// afterwards.
+func (img *SVGImage) Draw(ctx *Context, x, y, scale float32) {
+	ctx.Save()
+	ctx.Translate(x, y)
+	ctx.Scale(scale, scale)
+	var t = img.xform
+	ctx.Transform(t[0], t[1], t[2], t[3], t[4], t[5])
+	for _, shape := range img.shapes {
+		shape.draw(ctx)
+	}
+	ctx.Restore()
+}
+
+func (shape *svgShape) draw(ctx *Context) {
+	ctx.Save()
+	var t = shape.xform
+	ctx.Transform(t[0], t[1], t[2], t[3], t[4], t[5])
+	ctx.GlobalAlpha(ctx.CurrentGlobalAlpha() * shape.opacity)
+	ctx.BeginPath()
+	ctx.AppendPath(shape.path)
+	switch shape.fill.kind {
+	case svgPaintColor:
+		ctx.FillColor(shape.fill.color)
+		ctx.Fill()
+	case svgPaintGradient:
+		ctx.FillPaint(shape.fill.gradient.paint(ctx))
+		ctx.Fill()
+	}
+	if shape.stroke.kind != svgPaintNone && shape.strokeWidth > 0 {
+		switch shape.stroke.kind {
+		case svgPaintColor:
+			ctx.StrokeColor(shape.stroke.color)
+		case svgPaintGradient:
+			ctx.StrokePaint(shape.stroke.gradient.paint(ctx))
+		}
+		ctx.StrokeWidth(shape.strokeWidth)
+		ctx.LineCap(shape.lineCap)
+		ctx.LineJoin(shape.lineJoin)
+		ctx.MiterLimit(shape.miterLimit)
+		ctx.StrokeDash(shape.dashes, shape.dashOffset)
+		ctx.Stroke()
+	}
+	ctx.Restore()
+}
+
+// paint returns the gradient as a paint in the user space of its shape.
+func (g *svgGradient) paint(ctx *Context) Paint {
+	var paint Paint
+	if g.radial {
+		paint = ctx.RadialGradientStops(g.cx, g.cy, 0, g.r, g.stops)
+	} else {
+		paint = ctx.LinearGradientStops(g.x1, g.y1, g.x2, g.y2, g.stops)
+	}
+	var xform = paint.Transform()
+	TransformMultiply(&xform, g.xform)
+	return paint.WithTransform(xform)
+}
+
+// svgFillPath returns the path with the winding of each sub-path set so that
+// NanoVG fills it like the nonzero or evenodd fill rule of SVG. NanoVG fills
+// sub-paths with their enforced winding, so a sub-path is made a hole if the
+// area inside it is not filled by the fill rule. The direction of a sub-path
+// is the sign of the area of its flattened polygon, and the sub-paths around
+// it are counted at a point inside it.
+//
+// Sub-paths are assumed not to intersect themselves or each other: NanoVG
+// fills a self-intersecting sub-path with the nonzero rule, so evenodd is
+// ignored for a single sub-path.
+func svgFillPath(path *Path, evenOdd bool) *Path {
+	// Split the path into sub-paths, and flatten them into polygons.
+	type subPath struct {
+		commands []float32
+		polygon  []float32
+	}
+	var subPaths []*subPath
+	var current *subPath
+	var x0, y0 float32
+	var start = 0
+	path.walk(func(cmd int, args []float32) {
+		var n = 1 + len(args)
+		var commands = path.commands[start : start+n]
+		start += n
+		if cmd == pathMoveTo || current == nil {
+			current = &subPath{}
+			subPaths = append(subPaths, current)
+		}
+		current.commands = append(current.commands, commands...)
+		switch cmd {
+		case pathMoveTo, pathLineTo:
+			x0, y0 = args[0], args[1]
+			current.polygon = append(current.polygon, x0, y0)
+		case pathBezierTo:
+			const steps = 8
+			for i := 1; i <= steps; i++ {
+				var t = float32(i) / steps
+				current.polygon = append(current.polygon,
+					cubicAt(x0, args[0], args[2], args[4], t), cubicAt(y0, args[1], args[3], args[5], t))
+			}
+			x0, y0 = args[4], args[5]
+		}
+	})
+	if len(subPaths) < 2 {
+		return path
+	}
+
+	var result = &Path{}
+	for i, sp := range subPaths {
+		var area = polygonArea(sp.polygon)
+		var winding = Solid
+		if area != 0 && len(sp.polygon) >= 2 {
+			// Count the other sub-paths around a point inside this one.
+			var x, y = interiorPoint(sp.polygon, area)
+			var around, contained = 0, 0
+			for j, other := range subPaths {
+				if j == i {
+					continue
+				}
+				var w = windingNumber(other.polygon, x, y)
+				around += w
+				if w != 0 {
+					contained++
+				}
+			}
+			var own = 1
+			if area < 0 {
+				own = -1
+			}
+			var filled = around+own != 0
+			if evenOdd {
+				filled = contained%2 == 0
+			}
+			if !filled {
+				winding = Hole
+			}
+		}
+		result.commands = append(result.commands, sp.commands...)
+		result.PathWinding(winding)
+	}
+	result.lastX, result.lastY = path.lastX, path.lastY
+	return result
+}
+
+// polygonArea returns the signed area of a polygon of x, y pairs.
+func polygonArea(pts []float32) float32 {
+	var area float32
+	var n = len(pts) / 2
+	for i := 0; i < n; i++ {
+		var j = (i + 1) % n
+		area += pts[2*i]*pts[2*j+1] - pts[2*j]*pts[2*i+1]
+	}
+	return area / 2
+}
+
+// interiorPoint returns a point inside a polygon of x, y pairs with the signed
+// area area, next to the start of one of its edges, starting with the first.
+// It returns the first point of the polygon if no such point is found.
+func interiorPoint(pts []float32, area float32) (float32, float32) {
+	var n = len(pts) / 2
+	for i := 0; i < n; i++ {
+		var j = (i + 1) % n
+		var x0, y0, x1, y1 = pts[2*i], pts[2*i+1], pts[2*j], pts[2*j+1]
+		var dx, dy = x1 - x0, y1 - y0
+		var d = float32(math.Hypot(float64(dx), float64(dy)))
+		if d == 0 {
+			continue
+		}
+		// The inside is left of the edges of polygons with a positive area.
+		const offset = 1e-3
+		var nx, ny = -dy / d * offset, dx / d * offset
+		if area < 0 {
+			nx, ny = -nx, -ny
+		}
+		var x, y = x0 + dx/d*offset + nx, y0 + dy/d*offset + ny
+		if windingNumber(pts, x, y) != 0 {
+			return x, y
+		}
+	}
+	return pts[0], pts[1]
+}
+
+// windingNumber returns the winding number of a closed polygon of x, y pairs
+// around the point (x,y).
+func windingNumber(pts []float32, x, y float32) int {
+	var wn = 0
+	var n = len(pts) / 2
+	for i := 0; i < n; i++ {
+		var j = (i + 1) % n
+		var x0, y0, x1, y1 = pts[2*i], pts[2*i+1], pts[2*j], pts[2*j+1]
+		var side = (x1-x0)*(y-y0) - (x-x0)*(y1-y0)
+		if y0 <= y {
+			if y1 > y && side > 0 {
+				wn++
+			}
+		} else if y1 <= y && side < 0 {
+			wn--
+		}
+	}
+	return wn
+}
+
+// parseSVGNumbers parses a list of numbers separated by white space or
+// commas, up to the first invalid one.
+func parseSVGNumbers(s string) []float32 {
+	var parser = &svgPathParser{d: s}
+	var nums []float32
+	for parser.hasNumber() {
+		var f, err = parser.number()
+		if err != nil {
+			break
+		}
+		nums = append(nums, float32(f))
+	}
+	return nums
+}
+
+// parseSVGLength parses a length, whose percentages refer to ref. Font
+// relative units assume a font size of 16.
+func parseSVGLength(s string, ref float32) (float32, bool) {
+	s = strings.TrimSpace(s)
+	var units = []struct {
+		suffix string
+		scale  float32
+	}{
+		{"%", ref / 100},
+		{"px", 1},
+		{"pt", 4.0 / 3.0},
+		{"pc", 16},
+		{"mm", 96 / 25.4},
+		{"cm", 96 / 2.54},
+		{"in", 96},
+		{"em", 16},
+		{"ex", 8},
+	}
+	var scale float32 = 1
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(s[:len(s)-len(unit.suffix)])
+			scale = unit.scale
+			break
+		}
+	}
+	var f, err = strconv.ParseFloat(s, 32)
+	if err != nil {
+		return 0, false
+	}
+	return float32(f) * scale, true
+}
+
+// parseSVGOpacity parses an opacity as a number or percentage, returning def
+// if it is invalid.
+func parseSVGOpacity(s string, def float32) float32 {
+	var f, ok = parseSVGLength(s, 1)
+	if !ok {
+		return def
+	}
+	return clampf(f, 0, 1)
+}
+
+// parseSVGTransform parses the value of a transform attribute, up to the
+// first invalid transform.
+func parseSVGTransform(s string) [6]float32 {
+	var xform [6]float32
+	TransformIdentity(&xform)
+	for {
+		s = strings.TrimLeft(s, " \t\r\n,")
+		var open = strings.IndexByte(s, '(')
+		var close = strings.IndexByte(s, ')')
+		if open < 0 || close < open {
+			return xform
+		}
+		var name = strings.TrimSpace(s[:open])
+		var args = parseSVGNumbers(s[open+1 : close])
+		s = s[close+1:]
+
+		var t [6]float32
+		TransformIdentity(&t)
+		switch {
+		case name == "matrix" && len(args) == 6:
+			copy(t[:], args)
+		case name == "translate" && len(args) == 1:
+			TransformTranslate(&t, args[0], 0)
+		case name == "translate" && len(args) == 2:
+			TransformTranslate(&t, args[0], args[1])
+		case name == "scale" && len(args) == 1:
+			TransformScale(&t, args[0], args[0])
+		case name == "scale" && len(args) == 2:
+			TransformScale(&t, args[0], args[1])
+		case name == "rotate" && (len(args) == 1 || len(args) == 3):
+			TransformRotate(&t, DegToRad(args[0]))
+			if len(args) == 3 {
+				// Rotate around (cx,cy).
+				var t0, t1 [6]float32
+				TransformTranslate(&t0, -args[1], -args[2])
+				TransformTranslate(&t1, args[1], args[2])
+				TransformMultiply(&t0, t)
+				TransformMultiply(&t0, t1)
+				t = t0
+			}
+		case name == "skewX" && len(args) == 1:
+			TransformSkewX(&t, DegToRad(args[0]))
+		case name == "skewY" && len(args) == 1:
+			TransformSkewY(&t, DegToRad(args[0]))
+		default:
+			return xform
+		}
+		// The transforms of a list apply from right to left.
+		TransformPremultiply(&xform, t)
+	}
+}
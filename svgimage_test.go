@@ -0,0 +1,190 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func loadSVG(t *testing.T, doc string) *SVGImage {
+	t.Helper()
+	var img, err = LoadSVG(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("LoadSVG failed: %v", err)
+	}
+	return img
+}
+
+func renderSVG(t *testing.T, doc string) *image.RGBA {
+	t.Helper()
+	var svg = loadSVG(t, doc)
+	return renderSoftware(t, Antialias, func(ctx *Context) {
+		svg.Draw(ctx, 0, 0, 1)
+	})
+}
+
+func TestLoadSVGEncoding(t *testing.T) {
+	var tests = []struct {
+		encoding string
+		ok       bool
+	}{
+		{"UTF-8", true},
+		{"ISO-8859-1", true},
+		{"latin1", true},
+		{"US-ASCII", true},
+		{"Shift_JIS", false},
+	}
+	for _, test := range tests {
+		var doc = `<?xml version="1.0" encoding="` + test.encoding + `"?>` +
+			"<svg width=\"10\" height=\"10\"><title>Caf\xe9</title><rect width=\"10\" height=\"10\"/></svg>"
+		if test.encoding == "UTF-8" {
+			doc = strings.Replace(doc, "\xe9", "é", 1)
+		}
+		var img, err = LoadSVG(strings.NewReader(doc))
+		switch {
+		case test.ok && err != nil:
+			t.Errorf("%s: LoadSVG failed: %v", test.encoding, err)
+		case test.ok && len(img.shapes) != 1:
+			t.Errorf("%s: %d shapes, want 1", test.encoding, len(img.shapes))
+		case !test.ok && err == nil:
+			t.Errorf("%s: LoadSVG returned no error", test.encoding)
+		}
+	}
+}
+
+func TestSVGCharsetReader(t *testing.T) {
+	var r, err = svgCharsetReader("ISO-8859-1", strings.NewReader("Caf\xe9 \xff!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Read one byte at a time, splitting the UTF-8 sequences.
+	var got []byte
+	if got, err = io.ReadAll(iotest.OneByteReader(r)); err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "Café ÿ!" {
+		t.Errorf("read %q, want %q", got, "Café ÿ!")
+	}
+}
+
+func TestSVGViewBoxTransform(t *testing.T) {
+	var tests = []struct {
+		viewBox     [4]float32
+		aspectRatio string
+		want        [6]float32
+	}{
+		{[4]float32{0, 0, 100, 50}, "", [6]float32{2, 0, 0, 2, 0, 50}},
+		{[4]float32{0, 0, 100, 50}, "none", [6]float32{2, 0, 0, 4, 0, 0}},
+		{[4]float32{0, 0, 100, 50}, "xMinYMin", [6]float32{2, 0, 0, 2, 0, 0}},
+		{[4]float32{0, 0, 100, 50}, "xMaxYMax meet", [6]float32{2, 0, 0, 2, 0, 100}},
+		{[4]float32{0, 0, 100, 50}, "defer xMinYMax", [6]float32{2, 0, 0, 2, 0, 100}},
+		{[4]float32{0, 0, 100, 50}, "xMidYMid slice", [6]float32{4, 0, 0, 4, -100, 0}},
+		{[4]float32{0, 0, 100, 50}, "xMaxYMin slice", [6]float32{4, 0, 0, 4, -200, 0}},
+		{[4]float32{10, 20, 100, 50}, "", [6]float32{2, 0, 0, 2, -20, 10}},
+	}
+	for _, test := range tests {
+		if got := svgViewBoxTransform(test.viewBox, 200, 200, test.aspectRatio); got != test.want {
+			t.Errorf("svgViewBoxTransform(%v, %q) = %v, want %v", test.viewBox, test.aspectRatio, got, test.want)
+		}
+	}
+
+	var img = loadSVG(t, `<svg width="200" height="100" viewBox="0 0 100 100" preserveAspectRatio="xMaxYMid"/>`)
+	if img.Width != 200 || img.Height != 100 || img.xform != [6]float32{1, 0, 0, 1, 100, 0} {
+		t.Errorf("image of size %vx%v with transform %v, want 200x100 with %v", img.Width, img.Height, img.xform, [6]float32{1, 0, 0, 1, 100, 0})
+	}
+}
+
+func TestSVGGradientHref(t *testing.T) {
+	var img = renderSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="100" height="100">
+	<defs>
+		<linearGradient id="stops">
+			<stop offset="0" stop-color="red"/>
+			<stop offset="1" stop-color="blue"/>
+		</linearGradient>
+		<linearGradient id="vertical" xlink:href="#stops" x2="0" y2="1"/>
+		<linearGradient id="user" href="#vertical" gradientUnits="userSpaceOnUse" y1="50" y2="100"/>
+		<linearGradient id="cycle1" href="#cycle2"/>
+		<linearGradient id="cycle2" href="#cycle1"/>
+	</defs>
+	<rect width="50" height="50" fill="url(#vertical)"/>
+	<rect y="50" width="50" height="50" fill="url(#user)"/>
+	<rect x="50" width="50" height="100" fill="url(#cycle1) black"/>
+</svg>`)
+	checkPixels(t, img, []pixelTest{
+		// The stops of "stops" along the direction of "vertical".
+		{25, 0, color.RGBA{252, 0, 3, 255}},
+		{25, 49, color.RGBA{3, 0, 252, 255}},
+		{0, 25, color.RGBA{125, 0, 130, 255}},
+		{49, 25, color.RGBA{125, 0, 130, 255}},
+		// The same in user space.
+		{25, 50, color.RGBA{252, 0, 3, 255}},
+		{25, 99, color.RGBA{3, 0, 252, 255}},
+		// Gradients without stops use the fallback color.
+		{75, 50, black},
+	})
+}
+
+func TestSVGFillRule(t *testing.T) {
+	var tests = []struct {
+		rule string
+		d    string
+		want []pixelTest
+	}{
+		// A square inside a square of the same direction.
+		{"nonzero", "M10 10H90V90H10Z M30 30H70V70H30Z", []pixelTest{{20, 20, black}, {50, 50, black}, {5, 5, white}}},
+		{"evenodd", "M10 10H90V90H10Z M30 30H70V70H30Z", []pixelTest{{20, 20, black}, {50, 50, white}, {5, 5, white}}},
+		// A square inside a square of the opposite direction.
+		{"nonzero", "M10 10H90V90H10Z M30 30V70H70V30Z", []pixelTest{{20, 20, black}, {50, 50, white}}},
+		{"evenodd", "M10 10H90V90H10Z M30 30V70H70V30Z", []pixelTest{{20, 20, black}, {50, 50, white}}},
+		// Overlapping squares.
+		{"nonzero", "M10 10H60V60H10Z M40 40H90V90H40Z", []pixelTest{{20, 20, black}, {50, 50, black}, {80, 80, black}, {80, 20, white}}},
+		{"evenodd", "M10 10H60V60H10Z M40 40H90V90H40Z", []pixelTest{{20, 20, black}, {50, 50, white}, {80, 80, black}, {80, 20, white}}},
+		// Three nested squares of the same direction.
+		{"evenodd", "M0 0H100V100H0Z M20 20H80V80H20Z M40 40H60V60H40Z", []pixelTest{{10, 10, black}, {30, 30, white}, {50, 50, black}}},
+		// A triangle of the opposite direction starting on the edge of a square.
+		{"nonzero", "M10 10H90V90H10Z M90 50L50 30V70Z", []pixelTest{{20, 20, black}, {60, 50, white}}},
+		{"evenodd", "M10 10H90V90H10Z M90 50L50 30V70Z", []pixelTest{{20, 20, black}, {60, 50, white}}},
+		// A concave sub-path starting at its reflex corner.
+		{"evenodd", "M10 10H90V90H10Z M40 40V30H70V70H30V40Z", []pixelTest{{20, 20, black}, {35, 35, black}, {50, 50, white}}},
+	}
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			var img = renderSVG(t, `<svg width="100" height="100"><path fill-rule="`+test.rule+`" d="`+test.d+`"/></svg>`)
+			checkPixels(t, img, test.want)
+		})
+	}
+}
+
+func TestSVGDrawGlobalAlpha(t *testing.T) {
+	var svg = loadSVG(t, `<svg width="100" height="100"><rect width="100" height="100" opacity="0.5"/></svg>`)
+	var img = renderSoftware(t, Antialias, func(ctx *Context) {
+		ctx.GlobalAlpha(0.5)
+		svg.Draw(ctx, 0, 0, 1)
+		if alpha := ctx.CurrentGlobalAlpha(); alpha != 0.5 {
+			t.Errorf("CurrentGlobalAlpha() = %v after Draw, want 0.5", alpha)
+		}
+	})
+	// The opacity of the shape is multiplied by the global alpha.
+	checkPixels(t, img, []pixelTest{{50, 50, color.RGBA{191, 191, 191, 255}}})
+}
@@ -0,0 +1,382 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ParseSVGPath parses SVG path data, the d attribute of an SVG <path> element,
+// into a Path. All path commands are supported, in absolute and relative
+// forms: M, L, H, V, C, S, Q, T, A and Z. Elliptical arcs are converted to
+// cubic bezier segments.
+//
+// If the path data has an error, the path parsed up to the error is returned
+// along with the error, as SVG renders paths up to the first error.
+func ParseSVGPath(d string) (*Path, error) {
+	var p = &Path{}
+	var err = p.appendSVGPath(d)
+	return p, err
+}
+
+// SVGPath adds the sub-paths of SVG path data, the d attribute of an SVG
+// <path> element, to the current path. See ParseSVGPath() for the supported
+// commands. If the path data has an error, the sub-paths up to the error are
+// added and the error is returned.
+func (ctx *Context) SVGPath(d string) error {
+	var p, err = ParseSVGPath(d)
+	ctx.AppendPath(p)
+	return err
+}
+
+// svgPathParser parses SVG path data.
+type svgPathParser struct {
+	d   string
+	pos int
+}
+
+func (s *svgPathParser) skipSpace() {
+	for s.pos < len(s.d) {
+		switch s.d[s.pos] {
+		case ' ', '\t', '\n', '\r', '\f':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// skipSeparator skips white space and an optional comma.
+func (s *svgPathParser) skipSeparator() {
+	s.skipSpace()
+	if s.pos < len(s.d) && s.d[s.pos] == ',' {
+		s.pos++
+		s.skipSpace()
+	}
+}
+
+// hasNumber returns true if a number follows.
+func (s *svgPathParser) hasNumber() bool {
+	s.skipSpace()
+	if s.pos >= len(s.d) {
+		return false
+	}
+	switch c := s.d[s.pos]; {
+	case c >= '0' && c <= '9', c == '.', c == '-', c == '+':
+		return true
+	}
+	return false
+}
+
+func (s *svgPathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("nanovgo: invalid SVG path data at offset %d: %s", s.pos, fmt.Sprintf(format, args...))
+}
+
+// number parses a number followed by an optional separator.
+func (s *svgPathParser) number() (float64, error) {
+	s.skipSpace()
+	var start = s.pos
+	var digit = func() bool {
+		return s.pos < len(s.d) && s.d[s.pos] >= '0' && s.d[s.pos] <= '9'
+	}
+	if s.pos < len(s.d) && (s.d[s.pos] == '-' || s.d[s.pos] == '+') {
+		s.pos++
+	}
+	var digits = 0
+	for ; digit(); digits++ {
+		s.pos++
+	}
+	if s.pos < len(s.d) && s.d[s.pos] == '.' {
+		s.pos++
+		for ; digit(); digits++ {
+			s.pos++
+		}
+	}
+	if digits == 0 {
+		s.pos = start
+		return 0, s.errorf("expected a number")
+	}
+	if s.pos < len(s.d) && (s.d[s.pos] == 'e' || s.d[s.pos] == 'E') {
+		var mantissa = s.pos
+		s.pos++
+		if s.pos < len(s.d) && (s.d[s.pos] == '-' || s.d[s.pos] == '+') {
+			s.pos++
+		}
+		if !digit() {
+			// Not an exponent, as in "1em".
+			s.pos = mantissa
+		}
+		for digit() {
+			s.pos++
+		}
+	}
+	var text = s.d[start:s.pos]
+	var f, err = strconv.ParseFloat(text, 64)
+	if err != nil {
+		s.pos = start
+		return 0, s.errorf("invalid number %q", text)
+	}
+	s.skipSeparator()
+	return f, nil
+}
+
+// flag parses an arc flag, which may not be followed by a separator.
+func (s *svgPathParser) flag() (bool, error) {
+	s.skipSpace()
+	if s.pos >= len(s.d) || (s.d[s.pos] != '0' && s.d[s.pos] != '1') {
+		return false, s.errorf("expected a flag")
+	}
+	var f = s.d[s.pos] == '1'
+	s.pos++
+	s.skipSeparator()
+	return f, nil
+}
+
+// numbers parses len(dst) numbers into dst.
+func (s *svgPathParser) numbers(dst []float64) error {
+	for i := range dst {
+		var err error
+		if dst[i], err = s.number(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendSVGPath appends the sub-paths of SVG path data to the path.
+func (p *Path) appendSVGPath(d string) error {
+	var s = &svgPathParser{d: d}
+	// Current point, start of the current sub-path, and the last control
+	// point for S and T.
+	var x, y, startX, startY, ctrlX, ctrlY float64
+	var prev byte
+	// Whether a new sub-path has to be started at the current point, after Z.
+	var closed bool
+	var args [6]float64
+
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.d) {
+			return nil
+		}
+		var cmd = s.d[s.pos]
+		switch cmd {
+		case 'M', 'm', 'L', 'l', 'H', 'h', 'V', 'v', 'C', 'c', 'S', 's', 'Q', 'q', 'T', 't', 'A', 'a', 'Z', 'z':
+			s.pos++
+		default:
+			return s.errorf("unknown command %q", cmd)
+		}
+		if prev == 0 && cmd != 'M' && cmd != 'm' {
+			return s.errorf("path data must start with a move command")
+		}
+		var rel = cmd >= 'a'
+		var upper = cmd &^ 0x20
+
+		if upper == 'Z' {
+			p.ClosePath()
+			x, y = startX, startY
+			closed = true
+			prev = cmd
+			continue
+		}
+
+		// Commands repeat while numbers follow, and take at least one set of
+		// arguments.
+		for first := true; first || s.hasNumber(); first = false {
+			var ox, oy float64
+			if rel {
+				ox, oy = x, y
+			}
+			if closed && upper != 'M' {
+				p.MoveTo(float32(x), float32(y))
+			}
+			closed = false
+
+			switch upper {
+			case 'M':
+				if err := s.numbers(args[:2]); err != nil {
+					return err
+				}
+				x, y = ox+args[0], oy+args[1]
+				if first {
+					p.MoveTo(float32(x), float32(y))
+					startX, startY = x, y
+				} else {
+					// Following pairs are implicit line commands.
+					p.LineTo(float32(x), float32(y))
+				}
+			case 'L':
+				if err := s.numbers(args[:2]); err != nil {
+					return err
+				}
+				x, y = ox+args[0], oy+args[1]
+				p.LineTo(float32(x), float32(y))
+			case 'H':
+				if err := s.numbers(args[:1]); err != nil {
+					return err
+				}
+				x = ox + args[0]
+				p.LineTo(float32(x), float32(y))
+			case 'V':
+				if err := s.numbers(args[:1]); err != nil {
+					return err
+				}
+				y = oy + args[0]
+				p.LineTo(float32(x), float32(y))
+			case 'C', 'S':
+				var c1X, c1Y float64
+				if upper == 'C' {
+					if err := s.numbers(args[:6]); err != nil {
+						return err
+					}
+					c1X, c1Y = ox+args[0], oy+args[1]
+					ctrlX, ctrlY = ox+args[2], oy+args[3]
+				} else {
+					if err := s.numbers(args[:4]); err != nil {
+						return err
+					}
+					// Reflect the second control point of the previous cubic
+					// command.
+					c1X, c1Y = x, y
+					if last := prev &^ 0x20; last == 'C' || last == 'S' {
+						c1X, c1Y = 2*x-ctrlX, 2*y-ctrlY
+					}
+					ctrlX, ctrlY = ox+args[0], oy+args[1]
+					args[4], args[5] = args[2], args[3]
+				}
+				x, y = ox+args[4], oy+args[5]
+				p.BezierTo(float32(c1X), float32(c1Y), float32(ctrlX), float32(ctrlY), float32(x), float32(y))
+			case 'Q', 'T':
+				if upper == 'Q' {
+					if err := s.numbers(args[:4]); err != nil {
+						return err
+					}
+					ctrlX, ctrlY = ox+args[0], oy+args[1]
+				} else {
+					if err := s.numbers(args[:2]); err != nil {
+						return err
+					}
+					// Reflect the control point of the previous quadratic
+					// command.
+					if last := prev &^ 0x20; last == 'Q' || last == 'T' {
+						ctrlX, ctrlY = 2*x-ctrlX, 2*y-ctrlY
+					} else {
+						ctrlX, ctrlY = x, y
+					}
+					args[2], args[3] = args[0], args[1]
+				}
+				x, y = ox+args[2], oy+args[3]
+				p.QuadTo(float32(ctrlX), float32(ctrlY), float32(x), float32(y))
+			case 'A':
+				if err := s.numbers(args[:3]); err != nil {
+					return err
+				}
+				var largeArc, sweep, err = false, false, error(nil)
+				if largeArc, err = s.flag(); err != nil {
+					return err
+				}
+				if sweep, err = s.flag(); err != nil {
+					return err
+				}
+				if err = s.numbers(args[3:5]); err != nil {
+					return err
+				}
+				var x2, y2 = ox + args[3], oy + args[4]
+				p.svgArc(x, y, args[0], args[1], args[2], largeArc, sweep, x2, y2)
+				x, y = x2, y2
+			}
+			prev = cmd
+		}
+	}
+}
+
+// svgArc adds an SVG elliptical arc from (x1,y1) to (x2,y2) as cubic bezier
+// segments, following the endpoint to center conversion of the SVG
+// specification.
+func (p *Path) svgArc(x1, y1, rx, ry, angle float64, largeArc, sweep bool, x2, y2 float64) {
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.LineTo(float32(x2), float32(y2))
+		return
+	}
+
+	var sinPhi, cosPhi = math.Sincos(angle * math.Pi / 180)
+	var dx, dy = (x1 - x2) / 2, (y1 - y2) / 2
+	var x1p = cosPhi*dx + sinPhi*dy
+	var y1p = -sinPhi*dx + cosPhi*dy
+
+	// Scale up radii which are too small to reach the end point.
+	var lambda = x1p*x1p/(rx*rx) + y1p*y1p/(ry*ry)
+	if lambda > 1 {
+		lambda = math.Sqrt(lambda)
+		rx *= lambda
+		ry *= lambda
+	}
+
+	var num = rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	var den = rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	var coef = math.Sqrt(math.Max(0, num/den))
+	if largeArc == sweep {
+		coef = -coef
+	}
+	var cxp, cyp = coef * rx * y1p / ry, -coef * ry * x1p / rx
+	var cx = cosPhi*cxp - sinPhi*cyp + (x1+x2)/2
+	var cy = sinPhi*cxp + cosPhi*cyp + (y1+y2)/2
+
+	var vectorAngle = func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	var ux, uy = (x1p - cxp) / rx, (y1p - cyp) / ry
+	var vx, vy = (-x1p - cxp) / rx, (-y1p - cyp) / ry
+	var theta = vectorAngle(1, 0, ux, uy)
+	var delta = vectorAngle(ux, uy, vx, vy)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	// Split the arc into segments of at most 90 degrees.
+	var n = int(math.Ceil(math.Abs(delta)/(math.Pi/2) - 1e-9))
+	if n < 1 {
+		n = 1
+	}
+	var da = delta / float64(n)
+	var kappa = 4.0 / 3.0 * math.Tan(da/4)
+	var point = func(ex, ey float64) (float32, float32) {
+		return float32(cx + rx*cosPhi*ex - ry*sinPhi*ey), float32(cy + rx*sinPhi*ex + ry*cosPhi*ey)
+	}
+	for i := 0; i < n; i++ {
+		var a0, a1 = theta + da*float64(i), theta + da*float64(i+1)
+		var sin0, cos0 = math.Sincos(a0)
+		var sin1, cos1 = math.Sincos(a1)
+		var c1X, c1Y = point(cos0-kappa*sin0, sin0+kappa*cos0)
+		var c2X, c2Y = point(cos1+kappa*sin1, sin1-kappa*cos1)
+		var ex, ey = point(cos1, sin1)
+		if i == n-1 {
+			ex, ey = float32(x2), float32(y2)
+		}
+		p.BezierTo(c1X, c1Y, c2X, c2Y, ex, ey)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2018 Beta Kuang
+//
+// This software is provided 'as-is', without any express or implied
+// warranty.  In no event will the authors be held liable for any damages
+// arising from the use of this software.
+//
+// Permission is granted to anyone to use this software for any purpose,
+// including commercial applications, and to alter it and redistribute it
+// freely, subject to the following restrictions:
+//
+// 1. The origin of this software must not be misrepresented; you must not
+//    claim that you wrote the original software. If you use this software
+//    in a product, an acknowledgment in the product documentation would be
+//    appreciated but is not required.
+// 2. Altered source versions must be plainly marked as such, and must not be
+//    misrepresented as being the original software.
+// 3. This notice may not be removed or altered from any source distribution.
+
+package nanovgo
+
+import "testing"
+
+func TestParseSVGPath(t *testing.T) {
+	var tests = []struct {
+		d    string
+		want func(p *Path)
+	}{
+		{"M10 20L30 40", func(p *Path) {
+			p.MoveTo(10, 20)
+			p.LineTo(30, 40)
+		}},
+		{"M.5.5l.5-.5L1e1,2E-1", func(p *Path) {
+			p.MoveTo(0.5, 0.5)
+			p.LineTo(1, 0)
+			p.LineTo(10, 0.2)
+		}},
+		{"M0 0 10 0 10 10m5 5 1 1", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.LineTo(10, 0)
+			p.LineTo(10, 10)
+			p.MoveTo(15, 15)
+			p.LineTo(16, 16)
+		}},
+		{"M0 0l10 0 0 10H0v-5h2V0", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.LineTo(10, 0)
+			p.LineTo(10, 10)
+			p.LineTo(0, 10)
+			p.LineTo(0, 5)
+			p.LineTo(2, 5)
+			p.LineTo(2, 0)
+		}},
+		{"M0 0C0 10 10 10 10 0S20-10 20 0s10 10 10 0", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.BezierTo(0, 10, 10, 10, 10, 0)
+			p.BezierTo(10, -10, 20, -10, 20, 0)
+			p.BezierTo(20, 10, 30, 10, 30, 0)
+		}},
+		{"M0 0S10 10 20 0", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.BezierTo(0, 0, 10, 10, 20, 0)
+		}},
+		{"M0 0Q5 10 10 0T20 0t10 0", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.QuadTo(5, 10, 10, 0)
+			p.QuadTo(15, -10, 20, 0)
+			p.QuadTo(25, 10, 30, 0)
+		}},
+		{"M0 0L10 10T20 0", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.LineTo(10, 10)
+			p.QuadTo(10, 10, 20, 0)
+		}},
+		{"M10 10h10v10zm5 5h1", func(p *Path) {
+			p.MoveTo(10, 10)
+			p.LineTo(20, 10)
+			p.LineTo(20, 20)
+			p.ClosePath()
+			p.MoveTo(15, 15)
+			p.LineTo(16, 15)
+		}},
+		{"M10 10h10v10Zl5 5", func(p *Path) {
+			p.MoveTo(10, 10)
+			p.LineTo(20, 10)
+			p.LineTo(20, 20)
+			p.ClosePath()
+			p.MoveTo(10, 10)
+			p.LineTo(15, 15)
+		}},
+		{"M0 0A10 10 0 0 1 0 0", func(p *Path) {
+			p.MoveTo(0, 0)
+		}},
+		{"M0 0A0 10 0 0 1 20 0", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.LineTo(20, 0)
+		}},
+		{"", func(p *Path) {}},
+	}
+	for _, test := range tests {
+		var got, err = ParseSVGPath(test.d)
+		if err != nil {
+			t.Errorf("ParseSVGPath(%q) returned error: %v", test.d, err)
+			continue
+		}
+		var want Path
+		test.want(&want)
+		if !commandsNear(got.commands, want.commands) {
+			t.Errorf("ParseSVGPath(%q) = %v, want %v", test.d, got.commands, want.commands)
+		}
+	}
+}
+
+func TestParseSVGPathArcs(t *testing.T) {
+	var tests = []struct {
+		d    string
+		want [4]float32
+	}{
+		// Half circles on either side of the chord.
+		{"M0 0A10 10 0 0 0 20 0", [4]float32{0, 0, 20, 10}},
+		{"M0 0A10 10 0 0 1 20 0", [4]float32{0, -10, 20, 0}},
+		// Compact flags.
+		{"M0 0a10 10 0 0120 0", [4]float32{0, -10, 20, 0}},
+		{"M0 0A10 10 0 00 20 0", [4]float32{0, 0, 20, 10}},
+		// Large arcs of a circle of radius 10 through (0,0) and (10,10).
+		{"M0 0A10 10 0 1 1 10 10", [4]float32{0, -10, 20, 10}},
+		{"M0 0A10 10 0 1 0 10 10", [4]float32{-10, 0, 10, 20}},
+		// Radii too small are scaled up.
+		{"M0 0A1 1 0 0 1 20 0", [4]float32{0, -10, 20, 0}},
+		// Rotated ellipse.
+		{"M0 0A20 10 90 0 1 0 40", [4]float32{0, 0, 10, 40}},
+	}
+	for _, test := range tests {
+		var p, err = ParseSVGPath(test.d)
+		if err != nil {
+			t.Errorf("ParseSVGPath(%q) returned error: %v", test.d, err)
+			continue
+		}
+		if got := p.Bounds(); !commandsNear(got[:], test.want[:]) {
+			t.Errorf("ParseSVGPath(%q).Bounds() = %v, want %v", test.d, got, test.want)
+		}
+	}
+}
+
+func TestParseSVGPathErrors(t *testing.T) {
+	var tests = []struct {
+		d string
+		// The commands parsed up to the error.
+		want func(p *Path)
+	}{
+		{"L10 10", func(p *Path) {}},
+		{"10 10", func(p *Path) {}},
+		{"M0 0L10 10x", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.LineTo(10, 10)
+		}},
+		{"M0 0L10 10 20", func(p *Path) {
+			p.MoveTo(0, 0)
+			p.LineTo(10, 10)
+		}},
+		{"M0 0L10,,10", func(p *Path) {
+			p.MoveTo(0, 0)
+		}},
+		{"M0 0A10 10 0 2 1 20 0", func(p *Path) {
+			p.MoveTo(0, 0)
+		}},
+		{"M0 0L1e", func(p *Path) {
+			p.MoveTo(0, 0)
+		}},
+	}
+	for _, test := range tests {
+		var got, err = ParseSVGPath(test.d)
+		if err == nil {
+			t.Errorf("ParseSVGPath(%q) returned no error", test.d)
+			continue
+		}
+		var want Path
+		test.want(&want)
+		if !commandsNear(got.commands, want.commands) {
+			t.Errorf("ParseSVGPath(%q) = %v, want %v", test.d, got.commands, want.commands)
+		}
+	}
+}
+
+func commandsNear(c0, c1 []float32) bool {
+	if len(c0) != len(c1) {
+		return false
+	}
+	for i := range c0 {
+		if d := c0[i] - c1[i]; d > 1e-3 || d < -1e-3 {
+			return false
+		}
+	}
+	return true
+}